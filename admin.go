@@ -0,0 +1,199 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+)
+
+// The adminHandler is the handler created by AdminHandler.
+type adminHandler struct {
+	logger *Logger
+}
+
+// The adminOutput is the state of the output returned by the AdminHandler.
+type adminOutput struct {
+	Name       string        `json:"name"`
+	Levels     level.Level   `json:"levels"`
+	Layouts    layout.Layout `json:"layouts"`
+	Format     string        `json:"format"`
+	Enabled    bool          `json:"enabled"`
+	WithPrefix bool          `json:"withPrefix"`
+	WithColor  bool          `json:"withColor"`
+	Async      bool          `json:"async"`
+	Dropped    uint64        `json:"dropped,omitempty"`
+	RevertAt   *time.Time    `json:"revertAt,omitempty"`
+}
+
+// The adminState is the state of the logger returned by the AdminHandler.
+type adminState struct {
+	Prefix  string        `json:"prefix"`
+	Outputs []adminOutput `json:"outputs"`
+}
+
+// The adminRequest is the body of the PUT and PATCH requests.
+type adminRequest struct {
+	Outputs []OutputConfig `json:"outputs"`
+	Delete  []string       `json:"delete"`
+	TTL     string         `json:"ttl"`
+}
+
+// The plainOutputConfig is the OutputConfig without its
+// UnmarshalJSON method, it's decoded by the standard rules.
+type plainOutputConfig OutputConfig
+
+// AdminHandler returns the http.Handler that allows to inspect and
+// change the outputs of the logger at runtime:
+//   - GET returns the state of the outputs as JSON;
+//   - PATCH edits the outputs listed in the "outputs" of the body (see
+//     EditOutputs, the parameters have the format of OutputConfig, the
+//     destinations are ignored) and deletes the outputs listed in the
+//     "delete" of the body;
+//   - PUT works as PATCH, but also deletes the outputs that are not
+//     listed in the "outputs" of the body.
+//
+// If the body has the "ttl" duration (e.g. "15m"), the change is
+// temporary: the changed outputs are restored after the ttl. The
+// changes are applied all at once or not at all, the response
+// contains the new state of the outputs.
+//
+// Note: the handler has no authentication, it must be served
+// on the internal (admin) address only.
+//
+// Example usage:
+//
+//	http.Handle("/debug/log", log.AdminHandler(logger))
+//
+//	// curl -X PATCH localhost:8080/debug/log \
+//	//   -d '{"outputs": [{"name": "stdout", "levels": "all"}], "ttl": "10m"}'
+func AdminHandler(logger *Logger) http.Handler {
+	return &adminHandler{logger: logger.base()}
+}
+
+// ServeHTTP handles the request.
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPatch:
+		if err := h.change(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, PATCH")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.state())
+}
+
+// The change applies the body of the PUT or PATCH request.
+func (h *adminHandler) change(r *http.Request) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("incorrect body: %w", err)
+	}
+
+	// The OutputConfig decodes itself (see OutputConfig.UnmarshalJSON)
+	// and ignores the unknown fields, they are rejected by the strict
+	// decoding of the plain copy of the request.
+	var strict struct {
+		Outputs []plainOutputConfig `json:"outputs"`
+		Delete  []string            `json:"delete"`
+		TTL     string              `json:"ttl"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&strict); err != nil {
+		return fmt.Errorf("incorrect body: %w", err)
+	}
+
+	var req adminRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fmt.Errorf("incorrect body: %w", err)
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("incorrect ttl '%s'", req.TTL)
+		}
+
+		ttl = d
+	}
+
+	listed := make(map[string]bool, len(req.Outputs))
+	edits := make([]Output, 0, len(req.Outputs))
+	for _, oc := range req.Outputs {
+		listed[oc.Name] = true
+		o, err := oc.edit()
+		if err != nil {
+			return err
+		}
+
+		edits = append(edits, o)
+	}
+
+	deletes := req.Delete
+	if r.Method == http.MethodPut {
+		for _, o := range h.logger.Outputs() {
+			if !listed[o.Name] && !slices.Contains(deletes, o.Name) {
+				deletes = append(deletes, o.Name)
+			}
+		}
+	}
+
+	if len(edits) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	return h.logger.changeOutputs(edits, deletes, ttl)
+}
+
+// The state returns the state of the outputs of the logger.
+func (h *adminHandler) state() adminState {
+	outputs := h.logger.Outputs()
+	slices.SortFunc(outputs, func(a, b Output) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	result := adminState{
+		Prefix:  h.logger.Prefix(),
+		Outputs: make([]adminOutput, 0, len(outputs)),
+	}
+
+	for i := range outputs {
+		o := &outputs[i]
+		v := adminOutput{
+			Name:       o.Name,
+			Levels:     o.Levels,
+			Layouts:    o.Layouts,
+			Format:     outputFormat(o),
+			Enabled:    o.Enabled.IsTrue(),
+			WithPrefix: o.WithPrefix.IsTrue(),
+			WithColor:  o.WithColor.IsTrue(),
+			Async:      o.Async.IsTrue(),
+			Dropped:    o.Dropped(),
+		}
+
+		if t, ok := h.logger.revertTime(o.Name); ok {
+			v.RevertAt = &t
+		}
+
+		result.Outputs = append(result.Outputs, v)
+	}
+
+	return result
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// The adminRequest sends the request to the handler
+// and returns the response.
+func adminDo(
+	t *testing.T,
+	h http.Handler,
+	method, body string,
+) (*httptest.ResponseRecorder, adminState) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/log", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var state adminState
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+			t.Fatalf("invalid JSON %s: %v", rec.Body, err)
+		}
+	}
+
+	return rec, state
+}
+
+// TestAdminHandler tests the GET, PATCH and PUT requests.
+func TestAdminHandler(t *testing.T) {
+	logger := New("APP")
+	logger.SetOutputs(
+		Output{Name: "console", Writer: &bytes.Buffer{}, Levels: level.Info},
+		Output{Name: "file", Writer: &bytes.Buffer{}, Levels: level.Error},
+		Output{Name: "audit", Writer: &bytes.Buffer{}, Levels: level.Warn},
+	)
+	h := AdminHandler(logger)
+
+	rec, state := adminDo(t, h, http.MethodGet, "")
+	if rec.Code != http.StatusOK || state.Prefix != "APP" ||
+		len(state.Outputs) != 3 || state.Outputs[1].Name != "console" ||
+		state.Outputs[1].Levels != level.Info ||
+		state.Outputs[1].Format != "text" || !state.Outputs[1].Enabled {
+		t.Fatalf("incorrect state: %d %+v", rec.Code, state)
+	}
+
+	// PATCH.
+	rec, state = adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "levels": ">=warn", "enabled": false}],
+		"delete": ["audit"]
+	}`)
+	if rec.Code != http.StatusOK || len(state.Outputs) != 2 ||
+		state.Outputs[0].Levels != level.AtLeast(level.Warn) ||
+		state.Outputs[0].Enabled {
+		t.Fatalf("incorrect state: %d %+v", rec.Code, state)
+	}
+
+	// Incorrect requests don't change the outputs.
+	for _, body := range []string{
+		`{"outputs": [{"name": "console", "levels": "all"}, {"name": "x"}]}`,
+		`{"outputs": [{"name": "console", "levels": "verbose"}]}`,
+		`{"delete": ["missing"]}`,
+		`{"ttl": "soon"}`,
+		`{"unknown": true}`,
+		`{"outputs": [{"name": "console", "level": "all"}]}`,
+	} {
+		rec, _ = adminDo(t, h, http.MethodPatch, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+
+	if o := logger.Outputs("console")[0]; o.Levels != level.AtLeast(level.Warn) {
+		t.Errorf("the outputs were changed: %+v", o)
+	}
+
+	// The levels that select nothing disable the output.
+	rec, state = adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "file", "levels": "none"}]
+	}`)
+	if rec.Code != http.StatusOK || state.Outputs[1].Enabled ||
+		state.Outputs[1].Levels != level.Error {
+		t.Fatalf("incorrect state: %d %+v", rec.Code, state)
+	}
+
+	// PUT deletes the outputs that are not listed.
+	rec, state = adminDo(t, h, http.MethodPut, `{
+		"outputs": [{"name": "file", "format": "json"}]
+	}`)
+	if rec.Code != http.StatusOK || len(state.Outputs) != 1 ||
+		state.Outputs[0].Name != "file" || state.Outputs[0].Format != "json" {
+		t.Fatalf("incorrect state: %d %+v", rec.Code, state)
+	}
+
+	rec, _ = adminDo(t, h, http.MethodDelete, "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
+
+// TestAdminHandlerTTL tests the temporary changes.
+func TestAdminHandlerTTL(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(
+		Output{Name: "console", Writer: buf, Levels: level.AtLeast(level.Info)},
+		Output{Name: "debug", Writer: &bytes.Buffer{}, Levels: level.Debug},
+	)
+	h := AdminHandler(logger)
+
+	rec, state := adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "levels": "all"}],
+		"delete": ["debug"],
+		"ttl": "50ms"
+	}`)
+	if rec.Code != http.StatusOK || len(state.Outputs) != 1 ||
+		state.Outputs[0].Levels != level.Default ||
+		state.Outputs[0].RevertAt == nil {
+		t.Fatalf("incorrect state: %d %+v", rec.Code, state)
+	}
+
+	// The second temporary change extends the first one.
+	time.Sleep(20 * time.Millisecond)
+	adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "enabled": false}],
+		"ttl": "50ms"
+	}`)
+
+	time.Sleep(40 * time.Millisecond)
+	if o := logger.Outputs("console")[0]; o.Enabled.IsTrue() {
+		t.Fatalf("the change was reverted too early")
+	}
+
+	waitFor(t, func() bool { return len(logger.Outputs()) == 2 })
+	waitFor(t, func() bool {
+		o := logger.Outputs("console")[0]
+		return o.Enabled.IsTrue() && o.Levels == level.AtLeast(level.Info)
+	})
+
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(),
+			"temporary change of the output console reverted")
+	})
+
+	// The permanent change cancels the revert.
+	adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "levels": "error"}],
+		"ttl": "30ms"
+	}`)
+	adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "levels": "warn"}]
+	}`)
+
+	time.Sleep(60 * time.Millisecond)
+	if o := logger.Outputs("console")[0]; o.Levels != level.Warn {
+		t.Errorf("the permanent change was reverted: %s", o.Levels)
+	}
+}
+
+// TestAdminHandlerFallbacks tests that the changes that break
+// the fallbacks of the outputs are rejected.
+func TestAdminHandlerFallbacks(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(
+		Output{Name: "console", Writer: buf, Levels: level.Default},
+		Output{Name: "backup", Writer: &bytes.Buffer{}},
+		Output{Name: "file", Writer: &bytes.Buffer{}, Fallback: "backup"},
+	)
+	h := AdminHandler(logger)
+
+	// The edits and the deletes are applied all at once or not at all.
+	rec, _ := adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "console", "levels": "error"}],
+		"delete": ["backup"]
+	}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	if len(logger.Outputs()) != 3 ||
+		logger.Outputs("console")[0].Levels != level.Default {
+		t.Fatalf("the outputs were changed: %+v", logger.Outputs())
+	}
+
+	// The revert that breaks the fallbacks is not applied.
+	rec, _ = adminDo(t, h, http.MethodPatch, `{
+		"outputs": [{"name": "file", "fallback": "console"}],
+		"ttl": "30ms"
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec, _ = adminDo(t, h, http.MethodPatch, `{"delete": ["backup"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(),
+			"temporary change of the output file not reverted")
+	})
+
+	if o := logger.Outputs("file")[0]; o.Fallback != "console" {
+		t.Errorf("incorrect fallback: %s", o.Fallback)
+	}
+}
@@ -0,0 +1,270 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// OverflowBlock blocks the logging goroutine until there is
+	// free space in the queue of the asynchronous output.
+	OverflowBlock Overflow = iota + 1
+
+	// OverflowDropNewest drops the new message if the queue
+	// of the asynchronous output is full.
+	OverflowDropNewest
+
+	// OverflowDropOldest drops the oldest message in the queue
+	// of the asynchronous output to free space for the new one.
+	OverflowDropOldest
+)
+
+// Overflow is the type of the policy that determines what to do with
+// a new message when the queue of the asynchronous output is full.
+type Overflow uint8
+
+// The asyncItem is the element of the queue of the asynchronous output:
+// formatted message and the writer, or the flush marker.
+type asyncItem struct {
+	w       io.Writer
+	data    []byte
+	errs    *outputErrors // the error counter of the output, can be nil
+	record  *Record       // the record for the fallback output, can be nil
+	flushed chan struct{} // the flush marker, closed when it's reached
+}
+
+// The asyncQueue is the bounded queue of messages of the asynchronous
+// output. The messages are written to writers by the background goroutine.
+type asyncQueue struct {
+	ch       chan asyncItem
+	overflow Overflow
+	dropped  atomic.Uint64
+	done     chan struct{} // closed when the goroutines stop
+	closed   bool
+
+	// The fallbacks is the bounded queue of the records that failed to
+	// be written, they are passed to the fallback outputs by the single
+	// goroutine, see runFallbacks.
+	fallbacks chan asyncItem
+
+	// The mu protects the channel from being closed while
+	// the messages are being sent to it.
+	mu sync.RWMutex
+}
+
+// The newAsyncQueue creates the queue of the specified size
+// and starts its goroutines.
+func newAsyncQueue(size int, overflow Overflow) *asyncQueue {
+	q := &asyncQueue{
+		ch:        make(chan asyncItem, size),
+		overflow:  overflow,
+		done:      make(chan struct{}),
+		fallbacks: make(chan asyncItem, max(size, 1)),
+	}
+
+	go q.run()
+	go q.runFallbacks()
+	return q
+}
+
+// The run writes the messages from the queue to their writers
+// until the queue is closed.
+func (q *asyncQueue) run() {
+	defer close(q.fallbacks)
+	for item := range q.ch {
+		if item.flushed != nil {
+			// The flush waits for the fallbacks of the messages
+			// written before the marker too.
+			q.fallbacks <- item
+			continue
+		}
+
+		err := writeData(item.w, item.data)
+		if item.errs == nil {
+			continue
+		}
+
+		item.errs.done(err)
+		if err != nil && item.record != nil {
+			// The logger's lock cannot be taken by the goroutine of
+			// the queue, the logging goroutine can wait for it. So
+			// the record is passed to the goroutine of the fallbacks.
+			select {
+			case q.fallbacks <- item:
+			default:
+				item.errs.logger.reportError(item.errs.name,
+					errors.New("fallback queue is full, record dropped"))
+			}
+		}
+
+		item.errs.logger.handleErrors()
+	}
+}
+
+// The runFallbacks passes the records that failed to be written to the
+// fallback outputs until the queue is closed and its messages written.
+func (q *asyncQueue) runFallbacks() {
+	defer close(q.done)
+	for item := range q.fallbacks {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+
+		item.errs.logger.asyncFallback(item.errs.name, item.record)
+	}
+}
+
+// The push adds the message to the queue according to the overflow
+// policy. It returns false if the queue is closed, in this case the
+// message must be written synchronously.
+func (q *asyncQueue) push(
+	w io.Writer,
+	data []byte,
+	errs *outputErrors,
+	record *Record,
+) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	item := asyncItem{w: w, data: data, errs: errs, record: record}
+	switch q.overflow {
+	case OverflowDropNewest:
+		select {
+		case q.ch <- item:
+		default:
+			q.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- item:
+				return true
+			default:
+			}
+
+			// Free space. The flush marker is not a message: all the
+			// messages before it have already been written, so the
+			// flush is completed.
+			select {
+			case old := <-q.ch:
+				if old.flushed != nil {
+					close(old.flushed)
+				} else {
+					q.dropped.Add(1)
+				}
+			default:
+			}
+		}
+	default: // OverflowBlock
+		q.ch <- item
+	}
+
+	return true
+}
+
+// The flush waits until all the messages added to the queue
+// before the call are written, or the ctx is done.
+func (q *asyncQueue) flush(ctx context.Context) error {
+	marker := asyncItem{flushed: make(chan struct{})}
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return q.wait(ctx)
+	}
+
+	select {
+	case q.ch <- marker:
+		q.mu.RUnlock()
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-marker.flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// The close closes the queue and waits until all the messages
+// are written, or the ctx is done.
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	return q.wait(ctx)
+}
+
+// The isClosed returns true if the queue is closed.
+func (q *asyncQueue) isClosed() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed
+}
+
+// The wait waits for the goroutines of the closed queue to stop.
+func (q *asyncQueue) wait(ctx context.Context) error {
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of messages dropped by the asynchronous
+// output because its queue was full (see Output.Overflow).
+//
+// Example usage:
+//
+//	for _, o := range logger.Outputs() {
+//	    fmt.Println(o.Name, o.Dropped())
+//	}
+func (o *Output) Dropped() uint64 {
+	if o.queue == nil {
+		return 0
+	}
+
+	return o.queue.dropped.Load()
+}
+
+// The syncQueue creates, replaces or closes the queue of the output
+// according to its Async, BufferSize and Overflow parameters.
+// The replaced queue is closed in the background after it has
+// written its messages.
+func (o *Output) syncQueue() {
+	if !o.Async.IsTrue() {
+		if o.queue != nil {
+			go o.queue.close(context.Background())
+			o.queue = nil
+		}
+
+		return
+	}
+
+	if o.queue != nil && !o.queue.isClosed() &&
+		cap(o.queue.ch) == o.BufferSize && o.queue.overflow == o.Overflow {
+		return
+	}
+
+	if o.queue != nil {
+		go o.queue.close(context.Background())
+	}
+
+	o.queue = newAsyncQueue(o.BufferSize, o.Overflow)
+}
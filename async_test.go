@@ -0,0 +1,190 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// The slowWriter is the writer that waits for the signal before
+// each write, it's used to test the asynchronous outputs.
+type slowWriter struct {
+	release chan struct{}
+	buf     bytes.Buffer
+	mu      sync.Mutex
+}
+
+// Write writes p to the buffer after the release signal.
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+// String returns the contents of the buffer.
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// TestAsyncOutput tests the asynchronous output and the Flush method.
+func TestAsyncOutput(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{})}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "async",
+		Writer: w,
+		Levels: level.Default,
+		Async:  trit.True,
+	})
+	defer logger.Close()
+
+	// The logging goroutine is not blocked by the slow writer.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			logger.Infoln("message", i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the logging goroutine is blocked by the writer")
+	}
+
+	// Flush waits for the writer.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := logger.FlushContext(ctx); err == nil {
+		t.Error("expected the deadline error")
+	}
+
+	close(w.release)
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := w.String()
+	if strings.Count(out, "\n") != 10 ||
+		!strings.Contains(out, "message 0\n") ||
+		!strings.HasSuffix(out, "message 9\n") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
+
+// TestAsyncOverflow tests the overflow policies of the asynchronous output.
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow Overflow
+		want     string
+	}{
+		{"Drop newest", OverflowDropNewest, "message 1\n"},
+		{"Drop oldest", OverflowDropOldest, "message 4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &slowWriter{release: make(chan struct{})}
+			logger := New()
+			logger.SetOutputs(Output{
+				Name:       "async",
+				Writer:     w,
+				Levels:     level.Default,
+				Async:      trit.True,
+				BufferSize: 1,
+				Overflow:   tt.overflow,
+			})
+
+			// The first message is taken by the writer goroutine,
+			// wait for it to block on the writer.
+			logger.Infoln("message", 0)
+			for len(logger.outputs["async"].queue.ch) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			for i := 1; i < 5; i++ {
+				logger.Infoln("message", i)
+			}
+
+			close(w.release)
+			logger.Close()
+
+			if !strings.Contains(w.String(), tt.want) {
+				t.Errorf("expected `%s` in `%s`", tt.want, w.String())
+			}
+
+			o := logger.Outputs("async")[0]
+			if o.Dropped() != 3 {
+				t.Errorf("expected 3 dropped messages, got %d", o.Dropped())
+			}
+		})
+	}
+}
+
+// TestAsyncEdit tests switching the output to the asynchronous mode.
+func TestAsyncEdit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(Output{Name: "test", Writer: buf})
+
+	logger.EditOutputs(Output{Name: "test", Async: trit.True})
+	if logger.outputs["test"].queue == nil {
+		t.Fatal("the queue was not started")
+	}
+
+	logger.Info("async")
+	logger.Close()
+
+	logger.EditOutputs(Output{Name: "test", Async: trit.False})
+	if logger.outputs["test"].queue != nil {
+		t.Fatal("the queue was not stopped")
+	}
+
+	logger.Info("sync")
+	if out := buf.String(); !strings.Contains(out, "async") ||
+		!strings.HasSuffix(out, "sync") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
+
+// TestAsyncFatal tests that Fatal flushes the asynchronous outputs.
+func TestAsyncFatal(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{})}
+	close(w.release)
+
+	code := 0
+	exit = func(i int) { code = i }
+	defer func() {
+		exit = os.Exit
+	}()
+
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "async",
+		Writer: w,
+		Levels: level.Default,
+		Async:  trit.True,
+	})
+	defer logger.Close()
+
+	logger.Fatalln("fatal error")
+	if code != fatalStatusCode {
+		t.Errorf("expected status code %d, got %d", fatalStatusCode, code)
+	}
+
+	if !strings.Contains(w.String(), "fatal error") {
+		t.Errorf("the message was not flushed: %s", w.String())
+	}
+}
@@ -64,6 +64,33 @@ func BenchmarkLoggerInfoln(b *testing.B) {
 	}
 }
 
+// Benchmark the messages that no output accepts
+func BenchmarkLoggerRejected(b *testing.B) {
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "benchmark",
+		Writer: &nopWriter{},
+		Levels: level.Error,
+	})
+
+	b.Run("Levels", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			logger.Debugf("test message %d", i)
+		}
+	})
+
+	logger.EditOutputs(Output{
+		Name:    "benchmark",
+		Filters: []Filter{{Package: "internal/billing", Levels: level.Default}},
+	})
+
+	b.Run("Filters", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			logger.Debugf("test message %d", i)
+		}
+	})
+}
+
 // Benchmark different log levels
 func BenchmarkLogLevels(b *testing.B) {
 	logger := setupLogger()
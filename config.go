@@ -0,0 +1,418 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+	"github.com/goloop/log/network"
+	"github.com/goloop/trit"
+)
+
+const (
+	// The envPrefix is the prefix of the environment variables
+	// that override the configuration, see FromConfig.
+	envPrefix = "LOG_"
+
+	// The envOutputsPrefix is the prefix of the environment variables
+	// that override the configuration of a single output.
+	envOutputsPrefix = envPrefix + "OUTPUTS_"
+)
+
+// Config is the declarative configuration of the logger, it can be
+// decoded from JSON (or YAML with a decoder that supports json tags)
+// and applied by FromConfig.
+//
+// Example of the JSON configuration:
+//
+//	{
+//	    "prefix": "APP",
+//	    "outputs": [
+//	        {"name": "console", "destination": "stdout", "levels": ">=info"},
+//	        {
+//	            "name": "file",
+//	            "destination": "/var/log/app.log",
+//	            "levels": "error|fatal|panic",
+//	            "layouts": "full-path,line",
+//	            "format": "json"
+//	        }
+//	    ]
+//	}
+type Config struct {
+	// Prefix is the prefix of the logger.
+	Prefix string `json:"prefix,omitempty"`
+
+	// SkipStackFrames is the number of stack frames to skip,
+	// see Logger.SetSkipStackFrames. Zero means the default value.
+	SkipStackFrames int `json:"skipStackFrames,omitempty"`
+
+	// FatalStatusCode is the status code of the program termination
+	// by the Fatal* methods. Zero means the default value.
+	FatalStatusCode int `json:"fatalStatusCode,omitempty"`
+
+	// Outputs is the list of outputs. If it is empty, the outputs
+	// with the parameters of Stdout and Stderr are used.
+	Outputs []OutputConfig `json:"outputs,omitempty"`
+}
+
+// OutputConfig is the declarative configuration of the output. The empty
+// (or nil) values mean the default values of the Output.
+type OutputConfig struct {
+	// Name is the name of the output.
+	Name string `json:"name"`
+
+	// Destination is where the messages are written: "stdout",
+	// "stderr", the network address, e.g. "tcp://host:5170" (see
+	// network.Writer), or the path to the file. The file is created
+	// if necessary and opened for appending.
+	Destination string `json:"destination"`
+
+	// Levels is the levels of the output,
+	// e.g. "error|warn", ">=info" or "all,-trace". The levels
+	// that select nothing (e.g. "none" or "-trace") disable
+	// the output, the zero value is the default levels.
+	Levels level.Level `json:"levels,omitempty"`
+
+	// Layouts is the layouts of the output,
+	// e.g. "short-path,func,line". The layouts
+	// that select nothing (e.g. "none") are an error.
+	Layouts layout.Layout `json:"layouts,omitempty"`
+
+	// Format is the format of the messages: "text",
+	// "json" or "logfmt". By default, it is "text".
+	Format string `json:"format,omitempty"`
+
+	// Space is the separator between the parts of the message.
+	Space string `json:"space,omitempty"`
+
+	// WithPrefix, WithColor and Enabled are the flags of the output.
+	WithPrefix *bool `json:"withPrefix,omitempty"`
+	WithColor  *bool `json:"withColor,omitempty"`
+	Enabled    *bool `json:"enabled,omitempty"`
+
+	// TimestampFormat is the format of the timestamp.
+	TimestampFormat string `json:"timestampFormat,omitempty"`
+
+	// LevelFormat is the format of the level, e.g. "[%s]".
+	LevelFormat string `json:"levelFormat,omitempty"`
+
+	// Async and BufferSize are the parameters
+	// of the asynchronous output.
+	Async      bool `json:"async,omitempty"`
+	BufferSize int  `json:"bufferSize,omitempty"`
+
+	// Fallback is the name of the output that receives
+	// the messages this output failed to write.
+	Fallback string `json:"fallback,omitempty"`
+
+	// The noLevels and noLayouts are true if the levels or the layouts
+	// were set explicitly to the value that selects nothing, which
+	// can't be told from the omitted value by the zero Levels.
+	noLevels  bool
+	noLayouts bool
+}
+
+// UnmarshalJSON decodes the configuration of the output from JSON,
+// it tells the levels and layouts that select nothing (e.g. "none")
+// from the omitted ones.
+func (oc *OutputConfig) UnmarshalJSON(data []byte) error {
+	type plain OutputConfig
+	var probe struct {
+		Levels  *level.Level   `json:"levels"`
+		Layouts *layout.Layout `json:"layouts"`
+	}
+
+	if err := json.Unmarshal(data, (*plain)(oc)); err != nil {
+		return err
+	}
+
+	// The data is valid, it's decoded without errors once again.
+	json.Unmarshal(data, &probe)
+	oc.noLevels = probe.Levels != nil && *probe.Levels == 0
+	oc.noLayouts = probe.Layouts != nil && *probe.Layouts == 0
+	return nil
+}
+
+// FromConfig creates a new logger from the configuration. The files of
+// the outputs are opened by the function and closed by Logger.Close.
+//
+// The configuration can be overridden by the environment variables:
+//   - LOG_PREFIX is the prefix of the logger;
+//   - LOG_LEVEL is the levels of all the outputs, e.g. ">=warn",
+//     the levels that select nothing (e.g. "none") disable them;
+//   - LOG_FORMAT is the format of all the outputs: text, json or logfmt;
+//   - LOG_OUTPUTS_<NAME>_LEVELS, LOG_OUTPUTS_<NAME>_LAYOUTS,
+//     LOG_OUTPUTS_<NAME>_FORMAT and LOG_OUTPUTS_<NAME>_ENABLED override
+//     the parameters of the output, where <NAME> is the name of the output
+//     in upper case, with characters other than letters and digits
+//     replaced by underscores.
+//
+// The variables of the output take precedence over the global ones.
+//
+// Example usage:
+//
+//	var cfg log.Config
+//	if err := json.Unmarshal(data, &cfg); err != nil {
+//	    return err
+//	}
+//
+//	logger, err := log.FromConfig(cfg)
+//	if err != nil {
+//	    return err
+//	}
+//	defer logger.Close()
+func FromConfig(cfg Config) (*Logger, error) {
+	if len(cfg.Outputs) == 0 {
+		cfg.Outputs = []OutputConfig{
+			{Name: Stdout.Name, Destination: "stdout", Levels: Stdout.Levels},
+			{Name: Stderr.Name, Destination: "stderr", Levels: Stderr.Levels},
+		}
+	}
+
+	cfg, err := overlayEnv(cfg, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := New(cfg.Prefix)
+	if cfg.SkipStackFrames != 0 {
+		logger.SetSkipStackFrames(cfg.SkipStackFrames)
+	}
+
+	if cfg.FatalStatusCode != 0 {
+		logger.fatalStatusCode = cfg.FatalStatusCode
+	}
+
+	outputs := make([]Output, 0, len(cfg.Outputs))
+	for _, oc := range cfg.Outputs {
+		o, err := oc.output()
+		if err != nil {
+			closeOutputs(outputs)
+			return nil, err
+		}
+
+		// The errors of the background connection attempts are
+		// reported like the write errors of the output.
+		if w, ok := o.Writer.(*network.Writer); ok {
+			name := o.Name
+			w.ErrorHandler = func(err error) {
+				logger.reportError(name, err)
+				logger.handleErrors()
+			}
+		}
+
+		outputs = append(outputs, o)
+	}
+
+	if err := logger.SetOutputs(outputs...); err != nil {
+		closeOutputs(outputs)
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// The output creates the output from the configuration.
+func (oc OutputConfig) output() (Output, error) {
+	o, err := oc.edit()
+	if err != nil {
+		return o, err
+	}
+
+	switch oc.Destination {
+	case "":
+		return o, fmt.Errorf("the output '%s' has empty destination",
+			oc.Name)
+	case "stdout":
+		o.Writer = os.Stdout
+	case "stderr":
+		o.Writer = os.Stderr
+	default:
+		if strings.Contains(oc.Destination, "://") {
+			_, _, err := network.ParseAddress(oc.Destination)
+			if err != nil {
+				return o, fmt.Errorf("the output '%s': %w", oc.Name, err)
+			}
+
+			o.Writer = &network.Writer{Address: oc.Destination}
+			break
+		}
+
+		f, err := os.OpenFile(
+			oc.Destination,
+			os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+			0o644,
+		)
+		if err != nil {
+			return o, fmt.Errorf("the output '%s': %w", oc.Name, err)
+		}
+
+		o.Writer = f
+	}
+
+	return o, nil
+}
+
+// The edit creates the edit of the output from the configuration (see
+// Output.configure): the Writer is not set, the empty values of the
+// configuration don't change the output. The text and json formats
+// are set by the TextStyle, the logfmt format by the Formatter.
+func (oc OutputConfig) edit() (Output, error) {
+	o := Output{
+		Name:            oc.Name,
+		Levels:          oc.Levels,
+		Layouts:         oc.Layouts,
+		Space:           oc.Space,
+		WithPrefix:      boolTrit(oc.WithPrefix),
+		WithColor:       boolTrit(oc.WithColor),
+		Enabled:         boolTrit(oc.Enabled),
+		TimestampFormat: oc.TimestampFormat,
+		LevelFormat:     oc.LevelFormat,
+		BufferSize:      oc.BufferSize,
+		Fallback:        oc.Fallback,
+	}
+
+	if oc.Async {
+		o.Async = trit.True
+	}
+
+	// The zero values of the output mean the default values,
+	// so the levels that select nothing disable the output.
+	if oc.noLevels {
+		o.Enabled = trit.False
+	}
+
+	if oc.noLayouts {
+		return o, fmt.Errorf("the output '%s' has no layouts", oc.Name)
+	}
+
+	switch strings.ToLower(oc.Format) {
+	case "":
+	case "text":
+		o.TextStyle = trit.True
+	case "json":
+		o.TextStyle = trit.False
+	case "logfmt":
+		o.Formatter = LogfmtFormatter{}
+	default:
+		return o, fmt.Errorf("the output '%s' has unknown format '%s'",
+			oc.Name, oc.Format)
+	}
+
+	return o, nil
+}
+
+// The configure applies the edit created from the configuration (see
+// OutputConfig.edit) to the output as Output.merge, but the text and
+// json formats set by the TextStyle also replace the formatter of the
+// output by the built-in one.
+func (o *Output) configure(edit Output) {
+	o.merge(edit)
+	if edit.TextStyle != trit.Unknown {
+		o.Formatter = nil
+	}
+}
+
+// The overlayEnv returns the copy of the configuration overridden by
+// the environment variables, see FromConfig. The lookup returns the
+// value of the variable, e.g. os.LookupEnv.
+func overlayEnv(
+	cfg Config,
+	lookup func(key string) (string, bool),
+) (Config, error) {
+	var errs []error
+	if v, ok := lookup(envPrefix + "PREFIX"); ok {
+		cfg.Prefix = v
+	}
+
+	outputs := make([]OutputConfig, len(cfg.Outputs))
+	copy(outputs, cfg.Outputs)
+	cfg.Outputs = outputs
+
+	for i := range cfg.Outputs {
+		oc := &cfg.Outputs[i]
+		name := envOutputsPrefix + envName(oc.Name) + "_"
+
+		// The global variables are applied first.
+		for _, key := range []string{envPrefix + "LEVEL", name + "LEVELS"} {
+			if v, ok := lookup(key); ok {
+				l, err := level.Parse(v)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", key, err))
+					continue
+				}
+				oc.Levels, oc.noLevels = l, l == 0
+			}
+		}
+
+		for _, key := range []string{envPrefix + "FORMAT", name + "FORMAT"} {
+			if v, ok := lookup(key); ok {
+				oc.Format = v
+			}
+		}
+
+		if v, ok := lookup(name + "LAYOUTS"); ok {
+			l, err := layout.Parse(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%sLAYOUTS: %w", name, err))
+			} else {
+				oc.Layouts, oc.noLayouts = l, l == 0
+			}
+		}
+
+		if v, ok := lookup(name + "ENABLED"); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%sENABLED: %w", name, err))
+			} else {
+				oc.Enabled = &b
+			}
+		}
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// The envName converts the name of the output to the part of the name
+// of the environment variable: upper case, characters other than
+// letters and digits are replaced by underscores.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// The boolTrit converts the optional bool value to the trit value:
+// nil is the default value.
+func boolTrit(v *bool) trit.Trit {
+	switch {
+	case v == nil:
+		return trit.Unknown
+	case *v:
+		return trit.True
+	}
+
+	return trit.False
+}
+
+// The closeOutputs closes the files opened for the outputs.
+func closeOutputs(outputs []Output) {
+	for _, o := range outputs {
+		if c, ok := o.Writer.(io.Closer); ok && !isStdStream(o.Writer) {
+			c.Close()
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+)
+
+// TestFromConfig tests the FromConfig function.
+func TestFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	data := `{
+		"prefix": "APP",
+		"fatalStatusCode": 3,
+		"outputs": [
+			{"name": "console", "destination": "stdout", "levels": ">=info"},
+			{
+				"name": "error-file",
+				"destination": "` + path + `",
+				"levels": "error|fatal|panic",
+				"layouts": "full-path,line",
+				"format": "json",
+				"withColor": false
+			}
+		]
+	}`
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.Prefix() != "APP" || logger.fatalStatusCode != 3 {
+		t.Errorf("incorrect logger: %s, %d",
+			logger.Prefix(), logger.fatalStatusCode)
+	}
+
+	outputs := logger.Outputs("error-file")
+	if len(outputs) != 1 ||
+		outputs[0].Levels != level.AtLeast(level.Error) ||
+		outputs[0].Layouts != layout.FullFilePath|layout.LineNumber ||
+		!outputs[0].TextStyle.IsFalse() {
+		t.Fatalf("incorrect output: %+v", outputs)
+	}
+
+	logger.EditOutputs(Output{Name: "console", Enabled: -1})
+	logger.Errorln("failed")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := map[string]any{}
+	content, _ := os.ReadFile(path)
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", content, err)
+	}
+
+	if obj["message"] != "failed" || obj["prefix"] != "APP" {
+		t.Errorf("incorrect message: %s", content)
+	}
+}
+
+// TestFromConfigErrors tests the errors of the FromConfig function.
+func TestFromConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "Empty destination",
+			cfg:  Config{Outputs: []OutputConfig{{Name: "file"}}},
+		},
+		{
+			name: "Unknown format",
+			cfg: Config{Outputs: []OutputConfig{
+				{Name: "file", Destination: "stdout", Format: "xml"},
+			}},
+		},
+		{
+			name: "Incorrect name",
+			cfg: Config{Outputs: []OutputConfig{
+				{Name: "my file", Destination: "stdout"},
+			}},
+		},
+		{
+			name: "Missing directory",
+			cfg: Config{Outputs: []OutputConfig{{
+				Name:        "file",
+				Destination: filepath.Join(t.TempDir(), "no", "app.log"),
+			}}},
+		},
+		{
+			name: "Unsupported network",
+			cfg: Config{Outputs: []OutputConfig{
+				{Name: "net", Destination: "http://localhost:80"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromConfig(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestFromConfigNetwork tests the output with the network destination:
+// the connection errors are reported and the messages logged while the
+// receiver is down are sent by Close.
+func TestFromConfigNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	logger, err := FromConfig(Config{Outputs: []OutputConfig{{
+		Name:        "collector",
+		Destination: "unix://" + path,
+		Format:      "json",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The errors of the background attempts to connect are reported.
+	reports := make(chan error, 10)
+	logger.SetErrorHandler(func(output string, err error) {
+		if output == "collector" {
+			select {
+			case reports <- err:
+			default:
+			}
+		}
+	})
+
+	logger.Errorw("failed", "id", 7)
+	select {
+	case <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the connection error to be reported")
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	content, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", content, err)
+	}
+
+	if obj["message"] != "failed" || obj["id"] != 7.0 {
+		t.Errorf("incorrect message: %s", content)
+	}
+}
+
+// TestConfigEnv tests the environment overlay of the configuration.
+func TestConfigEnv(t *testing.T) {
+	t.Setenv("LOG_PREFIX", "ENV")
+	t.Setenv("LOG_LEVEL", ">=warn")
+	t.Setenv("LOG_FORMAT", "logfmt")
+	t.Setenv("LOG_OUTPUTS_ERROR_FILE_LEVELS", "all,-trace")
+	t.Setenv("LOG_OUTPUTS_ERROR_FILE_FORMAT", "json")
+	t.Setenv("LOG_OUTPUTS_ERROR_FILE_LAYOUTS", "func")
+	t.Setenv("LOG_OUTPUTS_CONSOLE_ENABLED", "false")
+
+	logger, err := FromConfig(Config{
+		Prefix: "APP",
+		Outputs: []OutputConfig{
+			{Name: "console", Destination: "stdout", Levels: level.Info},
+			{Name: "error-file", Destination: "stderr"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.Prefix() != "ENV" {
+		t.Errorf("expected ENV prefix, got %s", logger.Prefix())
+	}
+
+	console := logger.Outputs("console")[0]
+	if console.Levels != level.AtLeast(level.Warn) ||
+		console.Formatter != (LogfmtFormatter{}) ||
+		!console.Enabled.IsFalse() {
+		t.Errorf("incorrect console output: %+v", console)
+	}
+
+	file := logger.Outputs("error-file")[0]
+	if file.Levels != level.Default&^level.Trace ||
+		file.Layouts != layout.FuncName ||
+		!file.TextStyle.IsFalse() || file.Formatter != nil {
+		t.Errorf("incorrect error-file output: %+v", file)
+	}
+
+	// Incorrect values are reported.
+	t.Setenv("LOG_OUTPUTS_CONSOLE_ENABLED", "maybe")
+	_, err = FromConfig(Config{Outputs: []OutputConfig{
+		{Name: "console", Destination: "stdout"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "ENABLED") {
+		t.Errorf("expected the ENABLED error, got %v", err)
+	}
+}
+
+// TestConfigNoLevels tests that the levels that select
+// nothing disable the output instead of the default levels.
+func TestConfigNoLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		extra   string
+		env     map[string]string
+		enabled bool
+		err     bool
+	}{
+		{
+			name:    "Levels none",
+			extra:   `, "levels": "none"`,
+			enabled: false,
+		},
+		{
+			name:    "Exclusion only",
+			extra:   `, "levels": "-trace"`,
+			enabled: false,
+		},
+		{
+			name:    "Omitted levels",
+			enabled: true,
+		},
+		{
+			name:    "Environment none",
+			env:     map[string]string{"LOG_LEVEL": "none"},
+			enabled: false,
+		},
+		{
+			name: "Output environment takes precedence",
+			env: map[string]string{
+				"LOG_LEVEL":                  "none",
+				"LOG_OUTPUTS_CONSOLE_LEVELS": "error",
+			},
+			enabled: true,
+		},
+		{
+			name:  "Incorrect environment",
+			extra: `, "levels": "error"`,
+			env:   map[string]string{"LOG_LEVEL": "verbose"},
+			err:   true,
+		},
+		{
+			name:  "Layouts none",
+			extra: `, "layouts": "none"`,
+			err:   true,
+		},
+		{
+			name: "Environment layouts none",
+			env:  map[string]string{"LOG_OUTPUTS_CONSOLE_LAYOUTS": "none"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg Config
+			data := `{"outputs": [{"name": "console", ` +
+				`"destination": "stdout"` + tt.extra + `}]}`
+			if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			logger, err := FromConfig(cfg)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			o := logger.Outputs("console")[0]
+			if o.Enabled.IsTrue() != tt.enabled {
+				t.Errorf("expected enabled %v, got %+v", tt.enabled, o)
+			}
+		})
+	}
+
+	// The incorrect value doesn't change the levels.
+	cfg := Config{Outputs: []OutputConfig{
+		{Name: "console", Levels: level.Error},
+	}}
+	cfg, err := overlayEnv(cfg, func(key string) (string, bool) {
+		return "verbose", key == "LOG_LEVEL"
+	})
+	if err == nil || cfg.Outputs[0].Levels != level.Error {
+		t.Errorf("expected the error and unchanged levels: %v %s",
+			err, cfg.Outputs[0].Levels)
+	}
+}
+
+// TestConfigDefaults tests the configuration without outputs.
+func TestConfigDefaults(t *testing.T) {
+	t.Setenv("LOG_OUTPUTS_STDERR_LEVELS", "error")
+
+	logger, err := FromConfig(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	logger.EditOutputs(Output{Name: "stderr", Writer: buf})
+	logger.Warn("skipped")
+	logger.Error("written")
+	if out := buf.String(); !strings.Contains(out, "written") ||
+		strings.Contains(out, "skipped") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
@@ -0,0 +1,541 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/goloop/log/level"
+)
+
+// The contextKey is the type of the key of the logger in the context.
+type contextKey struct{}
+
+// ContextExtractor is a function that extracts request-scoped values
+// from the context. It returns a list of alternating keys and values
+// that are added to the message as fields (see Logger.With), or nil if
+// the context contains nothing to add.
+type ContextExtractor func(ctx context.Context) []any
+
+// ContextValue returns the ContextExtractor that adds the value of the
+// context key as the field with the specified name. Nothing is added if
+// the context doesn't contain the key.
+//
+// Example usage:
+//
+//	logger.AddContextExtractors(
+//	    log.ContextValue(requestIDKey, "request_id"),
+//	    log.ContextValue(tenantIDKey, "tenant_id"),
+//	)
+//	logger.InfoContext(ctx, "request accepted")
+func ContextValue(key any, name string) ContextExtractor {
+	return func(ctx context.Context) []any {
+		if v := ctx.Value(key); v != nil {
+			return []any{name, v}
+		}
+
+		return nil
+	}
+}
+
+// NewContext returns a copy of the parent context that carries
+// the logger. Use FromContext to get the logger from the context.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, see NewContext.
+// If the context doesn't carry a logger, the default logger is returned.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+			return logger
+		}
+	}
+
+	return self
+}
+
+// AddContextExtractors adds the context extractors to the default logger.
+func AddContextExtractors(extractors ...ContextExtractor) {
+	self.AddContextExtractors(extractors...)
+}
+
+// AddContextExtractors adds the context extractors to the logger.
+// The extractors are called by the *Context methods (InfoContext,
+// ErrorfContext etc.) and the values they return are added to the
+// message as fields: as top-level keys of the JSON object or as
+// key=value pairs of the header of the text message, before the
+// user's message (the other fields follow the message).
+func (logger *Logger) AddContextExtractors(extractors ...ContextExtractor) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	for _, e := range extractors {
+		if e != nil {
+			logger.extractors = append(logger.extractors, e)
+		}
+	}
+}
+
+// The contextFields returns the list of alternating keys and values
+// extracted from the context by the extractors of the logger.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) contextFields(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+
+	var kv []any
+	for _, e := range logger.extractors {
+		kv = append(kv, e(ctx)...)
+	}
+
+	return kv
+}
+
+// FpanicContext creates message with Panic level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FpanicContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Panic, nil, formatPrint, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprint(a...))
+}
+
+// FpanicfContext creates message with Panic level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FpanicfContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Panic, nil, format, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprintf(format, a...))
+}
+
+// FpaniclnContext creates message with Panic level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FpaniclnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Panic, nil, formatPrintln, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprintln(a...))
+}
+
+// PanicContext creates message with Panic level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) PanicContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Panic, nil, formatPrint, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprint(a...))
+}
+
+// PanicfContext creates message with Panic level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) PanicfContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Panic, nil, format, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprintf(format, a...))
+}
+
+// PaniclnContext creates message with Panic level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) PaniclnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Panic, nil, formatPrintln, a...)
+	logger.flushWithTimeout()
+	panic(fmt.Sprintln(a...))
+}
+
+// FfatalContext creates message with Fatal level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FfatalContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Fatal, nil, formatPrint, a...)
+	logger.terminate()
+}
+
+// FfatalfContext creates message with Fatal level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FfatalfContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Fatal, nil, format, a...)
+	logger.terminate()
+}
+
+// FfatallnContext creates message with Fatal level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FfatallnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Fatal, nil, formatPrintln, a...)
+	logger.terminate()
+}
+
+// FatalContext creates message with Fatal level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) FatalContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Fatal, nil, formatPrint, a...)
+	logger.terminate()
+}
+
+// FatalfContext creates message with Fatal level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FatalfContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Fatal, nil, format, a...)
+	logger.terminate()
+}
+
+// FatallnContext creates message with Fatal level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) FatallnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Fatal, nil, formatPrintln, a...)
+	logger.terminate()
+}
+
+// FerrorContext creates message with Error level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FerrorContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Error, nil, formatPrint, a...)
+}
+
+// FerrorfContext creates message with Error level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FerrorfContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Error, nil, format, a...)
+}
+
+// FerrorlnContext creates message with Error level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FerrorlnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Error, nil, formatPrintln, a...)
+}
+
+// ErrorContext creates message with Error level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) ErrorContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Error, nil, formatPrint, a...)
+}
+
+// ErrorfContext creates message with Error level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) ErrorfContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Error, nil, format, a...)
+}
+
+// ErrorlnContext creates message with Error level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) ErrorlnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Error, nil, formatPrintln, a...)
+}
+
+// FwarnContext creates message with Warn level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FwarnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Warn, nil, formatPrint, a...)
+}
+
+// FwarnfContext creates message with Warn level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FwarnfContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Warn, nil, format, a...)
+}
+
+// FwarnlnContext creates message with Warn level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FwarnlnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Warn, nil, formatPrintln, a...)
+}
+
+// WarnContext creates message with Warn level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) WarnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Warn, nil, formatPrint, a...)
+}
+
+// WarnfContext creates message with Warn level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) WarnfContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Warn, nil, format, a...)
+}
+
+// WarnlnContext creates message with Warn level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) WarnlnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Warn, nil, formatPrintln, a...)
+}
+
+// FinfoContext creates message with Info level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FinfoContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Info, nil, formatPrint, a...)
+}
+
+// FinfofContext creates message with Info level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FinfofContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Info, nil, format, a...)
+}
+
+// FinfolnContext creates message with Info level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FinfolnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Info, nil, formatPrintln, a...)
+}
+
+// InfoContext creates message with Info level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) InfoContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Info, nil, formatPrint, a...)
+}
+
+// InfofContext creates message with Info level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) InfofContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Info, nil, format, a...)
+}
+
+// InfolnContext creates message with Info level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) InfolnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Info, nil, formatPrintln, a...)
+}
+
+// FdebugContext creates message with Debug level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FdebugContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Debug, nil, formatPrint, a...)
+}
+
+// FdebugfContext creates message with Debug level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FdebugfContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Debug, nil, format, a...)
+}
+
+// FdebuglnContext creates message with Debug level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FdebuglnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Debug, nil, formatPrintln, a...)
+}
+
+// DebugContext creates message with Debug level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) DebugContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Debug, nil, formatPrint, a...)
+}
+
+// DebugfContext creates message with Debug level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) DebugfContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Debug, nil, format, a...)
+}
+
+// DebuglnContext creates message with Debug level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) DebuglnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Debug, nil, formatPrintln, a...)
+}
+
+// FtraceContext creates message with Trace level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FtraceContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Trace, nil, formatPrint, a...)
+}
+
+// FtracefContext creates message with Trace level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FtracefContext(
+	ctx context.Context,
+	w io.Writer,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Trace, nil, format, a...)
+}
+
+// FtracelnContext creates message with Trace level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) FtracelnContext(
+	ctx context.Context,
+	w io.Writer,
+	a ...any,
+) {
+	logger.echo(ctx, w, level.Trace, nil, formatPrintln, a...)
+}
+
+// TraceContext creates message with Trace level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) TraceContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Trace, nil, formatPrint, a...)
+}
+
+// TracefContext creates message with Trace level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func (logger *Logger) TracefContext(
+	ctx context.Context,
+	format string,
+	a ...any,
+) {
+	logger.echo(ctx, nil, level.Trace, nil, format, a...)
+}
+
+// TracelnContext creates message with Trace level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func (logger *Logger) TracelnContext(ctx context.Context, a ...any) {
+	logger.echo(ctx, nil, level.Trace, nil, formatPrintln, a...)
+}
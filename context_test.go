@@ -0,0 +1,146 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// The testContextKey is the type of the context key for tests.
+type testContextKey string
+
+// TestContextValue tests ContextValue function.
+func TestContextValue(t *testing.T) {
+	extractor := ContextValue(testContextKey("id"), "request_id")
+
+	ctx := context.Background()
+	if kv := extractor(ctx); kv != nil {
+		t.Errorf("expected nil, got %v", kv)
+	}
+
+	ctx = context.WithValue(ctx, testContextKey("id"), "abc")
+	kv := extractor(ctx)
+	if len(kv) != 2 || kv[0] != "request_id" || kv[1] != "abc" {
+		t.Errorf("expected [request_id abc], got %v", kv)
+	}
+}
+
+// TestNewContext tests NewContext and FromContext functions.
+func TestNewContext(t *testing.T) {
+	if FromContext(context.Background()) != self {
+		t.Error("expected the default logger")
+	}
+
+	logger := New()
+	ctx := NewContext(context.Background(), logger)
+	if FromContext(ctx) != logger {
+		t.Error("expected the logger from the context")
+	}
+}
+
+// TestContextMethods tests the *Context methods of the Logger.
+func TestContextMethods(t *testing.T) {
+	logger := New()
+	buf := &bytes.Buffer{}
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+	logger.AddContextExtractors(
+		ContextValue(testContextKey("id"), "request_id"),
+		ContextValue(testContextKey("tenant"), "tenant_id"),
+	)
+
+	ctx := context.WithValue(
+		context.Background(),
+		testContextKey("id"),
+		"abc",
+	)
+
+	// The fields of the context are in the header of the text message,
+	// the fields of the call follow the message.
+	logger.With("user_id", 7).InfolnContext(ctx, "request", "accepted")
+	want := "request_id=abc request accepted user_id=7\n"
+	if out := buf.String(); !strings.HasSuffix(out, want) {
+		t.Errorf("expected `%s` in `%s`", want, out)
+	}
+
+	// JSON style.
+	buf.Reset()
+	logger.EditOutputs(Output{Name: "test", TextStyle: trit.False})
+	ctx = context.WithValue(ctx, testContextKey("tenant"), 7)
+	logger.ErrorfContext(ctx, "failed %d times", 3)
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if obj["message"] != "failed 3 times" ||
+		obj["request_id"] != "abc" ||
+		obj["tenant_id"] != float64(7) {
+		t.Errorf("incorrect JSON message: %s", buf.String())
+	}
+
+	// The slog handler uses the extractors too.
+	buf.Reset()
+	slog.New(NewSlogHandler(logger)).WarnContext(ctx, "slow")
+	if !strings.Contains(buf.String(), `"request_id":"abc"`) {
+		t.Errorf("incorrect JSON message: %s", buf.String())
+	}
+
+	// Writer of the call.
+	w := &bytes.Buffer{}
+	logger.FdebugContext(ctx, w, "to writer")
+	if !strings.Contains(w.String(), "request_id=abc") {
+		t.Errorf("incorrect message: %s", w.String())
+	}
+}
+
+// TestContextExtractorsCopy tests that the extractors added to
+// the copies of the same logger don't overwrite each other.
+func TestContextExtractorsCopy(t *testing.T) {
+	tests := []struct {
+		name string
+		copy func(logger *Logger) *Logger
+	}{
+		{"Copy", func(logger *Logger) *Logger { return logger.Copy() }},
+		{"Named", func(logger *Logger) *Logger { return logger.Named("app") }},
+		{"With", func(logger *Logger) *Logger { return logger.With() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := New()
+			logger.SetOutputs(Output{Name: "test", Writer: &bytes.Buffer{}})
+
+			// The spare capacity of the list of the extractors.
+			for _, key := range []string{"a", "b", "c"} {
+				logger.AddContextExtractors(
+					ContextValue(testContextKey(key), key))
+			}
+
+			first, second := tt.copy(logger), tt.copy(logger)
+			first.AddContextExtractors(ContextValue(testContextKey("x"), "x"))
+			second.AddContextExtractors(ContextValue(testContextKey("y"), "y"))
+
+			ctx := context.WithValue(context.Background(),
+				testContextKey("x"), "X")
+			ctx = context.WithValue(ctx, testContextKey("y"), "Y")
+
+			buf := &bytes.Buffer{}
+			first.FinfoContext(ctx, buf, "first")
+			if out := buf.String(); !strings.Contains(out, "x=X") ||
+				strings.Contains(out, "y=Y") {
+				t.Errorf("incorrect fields of the first copy: %s", out)
+			}
+		})
+	}
+}
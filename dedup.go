@@ -0,0 +1,176 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// The dedupKey identifies the repeated records: the records with
+// the same level, caller and rendered message.
+type dedupKey struct {
+	prefix  string
+	level   level.Level
+	file    string
+	line    int
+	message string
+	fields  string
+}
+
+// The newDedupKey returns the key of the record.
+func newDedupKey(r *Record) dedupKey {
+	key := dedupKey{
+		prefix:  r.Prefix,
+		level:   r.Level,
+		file:    r.FilePath,
+		line:    r.LineNumber,
+		message: r.Message,
+	}
+
+	if len(r.Fields) != 0 {
+		key.fields = textFields(" ", r.Fields)
+	}
+
+	return key
+}
+
+// The deduper suppresses the consecutive repeated records of the
+// output, like syslogd does, see Output.Dedup.
+type deduper struct {
+	timeout time.Duration
+	last    dedupKey
+	record  *Record     // last written record
+	count   int         // number of suppressed repeats
+	timer   *time.Timer // report of the repeats by timeout
+	mu      sync.Mutex
+}
+
+// The check returns true if the record is a repeat of the last record
+// and must be suppressed. Otherwise, it returns the report of the
+// suppressed repeats of the previous record, if any, which must be
+// written before the record.
+//
+// The timeout function is called after the timeout since the first
+// suppressed repeat.
+func (d *deduper) check(r *Record, timeout func()) (bool, *Record) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := newDedupKey(r)
+	if d.record != nil && key == d.last {
+		d.count++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.timeout, timeout)
+		}
+
+		return true, nil
+	}
+
+	report := d.report()
+	d.last, d.record = key, r
+	return false, report
+}
+
+// The flush returns the report of the suppressed repeats, if any.
+func (d *deduper) flush() *Record {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.report()
+}
+
+// The report returns the record that reports the number of the
+// suppressed repeats and resets it, or nil if there are no repeats.
+//
+// The method must be called with the deduper's lock held.
+func (d *deduper) report() *Record {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.count == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("message repeated %d times", d.count)
+	r := newRecord(d.record.Prefix, d.record.Level, d.record.stackFrame(),
+		nil, formatPrintln, []any{msg})
+	r.synthetic = true
+	d.count = 0
+	return r
+}
+
+// The syncDeduper creates, replaces or removes the deduper
+// of the output according to its Dedup.
+func (o *Output) syncDeduper() {
+	switch {
+	case o.Dedup <= 0:
+		o.deduper = nil
+	case o.deduper == nil || o.deduper.timeout != o.Dedup:
+		o.deduper = &deduper{timeout: o.Dedup}
+	}
+}
+
+// The dedup returns true if the record must be written to the output,
+// and false for the repeat of the last record of the output. The report
+// of the repeats of the previous record is written before the record.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) dedup(name string, o *Output, r *Record) bool {
+	d := o.deduper
+	if d == nil || r.synthetic {
+		return true
+	}
+
+	r.render() // the repeats are compared by the message
+	repeat, report := d.check(r, func() {
+		logger.flushRepeats(name, d)
+	})
+
+	if report != nil {
+		logger.write(name, o, report)
+	}
+
+	return !repeat
+}
+
+// The flushRepeats writes the report of the repeats suppressed by
+// the deduper to the output, if the output still has the deduper.
+func (logger *Logger) flushRepeats(name string, d *deduper) {
+	defer logger.handleErrors()
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	o, ok := logger.outputs[name]
+	if !ok || o.deduper != d || logger.closed {
+		return
+	}
+
+	if r := d.flush(); r != nil {
+		logger.write(name, o, r)
+	}
+}
+
+// The flushAllRepeats writes the reports of the suppressed repeats
+// of all outputs, it's called by Flush and Close.
+func (logger *Logger) flushAllRepeats() {
+	defer logger.handleErrors()
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	if logger.closed {
+		return
+	}
+
+	for name, o := range logger.outputs {
+		if o.deduper == nil {
+			continue
+		}
+
+		if r := o.deduper.flush(); r != nil {
+			logger.write(name, o, r)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// TestOutputDedup tests the suppression of the repeated records.
+func TestOutputDedup(t *testing.T) {
+	tests := []struct {
+		name  string
+		style trit.Trit
+		check func(t *testing.T, out string)
+	}{
+		{
+			name:  "Text style",
+			style: trit.True,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				if len(lines) != 4 {
+					t.Fatalf("expected 4 lines, got %d: %s", len(lines), out)
+				}
+
+				if !strings.Contains(lines[0], "disk full") ||
+					!strings.Contains(lines[1], "message repeated 9 times") ||
+					!strings.Contains(lines[1], "dedup_test.go:") ||
+					!strings.Contains(lines[2], "disk ok") ||
+					!strings.Contains(lines[3], "disk full") {
+					t.Errorf("incorrect output: %s", out)
+				}
+			},
+		},
+		{
+			name:  "JSON style",
+			style: trit.False,
+			check: func(t *testing.T, out string) {
+				dec := json.NewDecoder(strings.NewReader(out))
+				var messages []string
+				for dec.More() {
+					var obj struct{ Message, Level string }
+					if err := dec.Decode(&obj); err != nil {
+						t.Fatal(err)
+					}
+
+					messages = append(messages, obj.Level+" "+obj.Message)
+				}
+
+				expected := []string{
+					"ERROR disk full",
+					"ERROR message repeated 9 times",
+					"INFO disk ok",
+					"ERROR disk full",
+				}
+
+				if strings.Join(messages, ";") != strings.Join(expected, ";") {
+					t.Errorf("incorrect output: %v", messages)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &syncBuffer{}
+			logger := New()
+			logger.SetOutputs(Output{
+				Name:      "test",
+				Writer:    buf,
+				Levels:    level.Default,
+				TextStyle: test.style,
+				Dedup:     time.Hour,
+			})
+
+			for i := 0; i < 10; i++ {
+				logger.Errorln("disk full")
+			}
+
+			logger.Infoln("disk ok")
+			logger.Errorln("disk full")
+			test.check(t, buf.String())
+		})
+	}
+}
+
+// TestOutputDedupTimeout tests the report of the repeated records
+// by timeout and by Flush.
+func TestOutputDedupTimeout(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+		Dedup:  50 * time.Millisecond,
+	})
+
+	retry := func(n int) {
+		for i := 0; i < n; i++ {
+			logger.Warnln("retrying")
+		}
+	}
+
+	retry(5)
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(), "message repeated 4 times")
+	})
+
+	// The repeats are counted again after the report.
+	retry(2)
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Count(out, "retrying") != 1 ||
+		!strings.Contains(out, "message repeated 2 times") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
+
+// TestOutputDedupCaller tests that the records from
+// different callers are not repeats.
+func TestOutputDedupCaller(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+		Dedup:  time.Hour,
+	})
+
+	logger.Infoln("same")
+	logger.Infoln("same")
+	for i := 0; i < 2; i++ {
+		logger.Infoln("same")
+	}
+	logger.Flush()
+
+	out := buf.String()
+	if strings.Count(out, "same") != 3 ||
+		!strings.Contains(out, "message repeated 1 times") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
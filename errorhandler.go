@@ -0,0 +1,266 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goloop/g"
+	"github.com/goloop/trit"
+)
+
+const (
+	// The reprobeInterval is the default interval after which
+	// the output disabled by the errors is enabled again.
+	reprobeInterval = 10 * time.Second
+
+	// The maxReprobeInterval is the maximum interval of the
+	// re-probes of the output that keeps failing.
+	maxReprobeInterval = 10 * time.Minute
+)
+
+// ErrorHandler handles the errors of the outputs that cannot be returned
+// to the caller: the errors of the formatters and the writers, e.g. when
+// the disk is full. The output is the name of the output.
+//
+// The handler is called after the logger's lock is released by the
+// goroutine that logged the record (or by another logging goroutine,
+// or by the background goroutine of the asynchronous output), so it
+// can log messages and change the outputs. It must not block.
+type ErrorHandler func(output string, err error)
+
+// The outputError is the error of the output that waits for the handler.
+type outputError struct {
+	output string
+	err    error
+}
+
+// The outputErrors is the error counter of the output, it disables
+// the output after the consecutive failures, see Output.DisableAfter.
+type outputErrors struct {
+	logger *Logger
+	name   string
+
+	total        atomic.Uint64
+	consecutive  atomic.Uint64
+	disableAfter atomic.Int64
+	reprobeAfter atomic.Int64
+	disabled     atomic.Bool // the output is disabled by the counter
+
+	backoff time.Duration // interval of the next re-probe
+	mu      sync.Mutex
+}
+
+// The fail counts the error of the output and reports it. If the
+// write is true, it's the write error, it disables the output after
+// the specified number of consecutive write errors.
+func (e *outputErrors) fail(err error, write bool) {
+	e.total.Add(1)
+	e.logger.reportError(e.name, err)
+	if !write {
+		return
+	}
+
+	n := e.consecutive.Add(1)
+	limit := e.disableAfter.Load()
+	if limit > 0 && n >= uint64(limit) && e.disabled.CompareAndSwap(false, true) {
+		// The output is changed with the logger's lock held,
+		// the write can be made with the logger's read lock held.
+		go e.logger.disableOutput(e, n)
+	}
+}
+
+// The done handles the result of the write.
+func (e *outputErrors) done(err error) {
+	if err != nil {
+		e.fail(err, true)
+		return
+	}
+
+	// The successful write resets the consecutive errors.
+	if e.consecutive.Load() != 0 {
+		e.consecutive.Store(0)
+		e.mu.Lock()
+		e.backoff = 0
+		e.mu.Unlock()
+	}
+}
+
+// The next returns the interval of the next re-probe:
+// it's doubled after each failed re-probe.
+func (e *outputErrors) next() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	first := time.Duration(e.reprobeAfter.Load())
+	switch {
+	case e.backoff == 0:
+		e.backoff = first
+	case e.backoff < max(first, maxReprobeInterval):
+		e.backoff = min(2*e.backoff, max(first, maxReprobeInterval))
+	}
+
+	return e.backoff
+}
+
+// Errors returns the number of errors of the output: the errors
+// of the formatter and the writer, see SetErrorHandler.
+func (o *Output) Errors() uint64 {
+	if o.errs == nil {
+		return 0
+	}
+
+	return o.errs.total.Load()
+}
+
+// ConsecutiveErrors returns the number of the consecutive
+// write errors of the output, see Output.DisableAfter.
+func (o *Output) ConsecutiveErrors() uint64 {
+	if o.errs == nil {
+		return 0
+	}
+
+	return o.errs.consecutive.Load()
+}
+
+// The syncErrors creates the error counter of the output
+// of the logger and updates its parameters.
+func (o *Output) syncErrors(logger *Logger) {
+	if o.errs == nil || o.errs.logger != logger || o.errs.name != o.Name {
+		o.errs = &outputErrors{logger: logger, name: o.Name}
+	}
+
+	o.errs.disableAfter.Store(int64(o.DisableAfter))
+	o.errs.reprobeAfter.Store(int64(g.Value(o.ReprobeAfter, reprobeInterval)))
+}
+
+// SetErrorHandler sets the handler of the errors of the outputs
+// of the default logger.
+func SetErrorHandler(h ErrorHandler) {
+	self.SetErrorHandler(h)
+}
+
+// SetErrorHandler sets the handler of the errors of the outputs that
+// cannot be returned to the caller, e.g. the write errors. By default,
+// or if the handler is nil, the errors are printed to os.Stderr.
+//
+// Example usage:
+//
+//	logger.SetErrorHandler(func(output string, err error) {
+//	    metrics.Inc("log_errors", output)
+//	})
+func (logger *Logger) SetErrorHandler(h ErrorHandler) {
+	// The named logger uses the outputs of the root logger.
+	logger = logger.base()
+	if h == nil {
+		logger.errorHandler.Store(nil)
+		return
+	}
+
+	logger.errorHandler.Store(&h)
+}
+
+// The reportError reports the error of the output that cannot be
+// returned to the caller. The error is passed to the handler by the
+// handleErrors, the caller that holds the logger's lock must call
+// it after the lock is released.
+func (logger *Logger) reportError(output string, err error) {
+	logger.emu.Lock()
+	logger.pendingErrors = append(logger.pendingErrors,
+		outputError{output, err})
+	logger.hasErrors.Store(true)
+	logger.emu.Unlock()
+}
+
+// The handleErrors passes the reported errors to the error handlers,
+// see reportError and reportHookError. It must be called without the
+// logger's lock held.
+func (logger *Logger) handleErrors() {
+	if !logger.hasErrors.Load() {
+		return
+	}
+
+	logger.emu.Lock()
+	errs, hookErrs := logger.pendingErrors, logger.pendingHookErrors
+	logger.pendingErrors, logger.pendingHookErrors = nil, nil
+	logger.hasErrors.Store(false)
+	logger.emu.Unlock()
+
+	h := logger.errorHandler.Load()
+	for _, e := range errs {
+		if h != nil {
+			(*h)(e.output, e.err)
+		} else {
+			fmt.Fprintf(os.Stderr, "log: output %s: %v\n", e.output, e.err)
+		}
+	}
+
+	for _, e := range hookErrs {
+		e.handle()
+	}
+}
+
+// The written handles the result of the write to the output.
+func (logger *Logger) written(name string, o *Output, err error) {
+	switch {
+	case o.errs != nil:
+		o.errs.done(err)
+	case err != nil:
+		logger.reportError(name, err)
+	}
+}
+
+// The disableOutput disables the output after n consecutive
+// errors and schedules its re-probe.
+func (logger *Logger) disableOutput(e *outputErrors, n uint64) {
+	logger.mu.Lock()
+	o, ok := logger.outputs[e.name]
+	if !ok || o.errs != e || !o.Enabled.IsTrue() {
+		logger.mu.Unlock()
+		e.disabled.Store(false)
+		return
+	}
+
+	disabled := *o
+	disabled.Enabled = trit.False
+	logger.outputs[e.name] = &disabled
+	logger.mu.Unlock()
+
+	d := e.next()
+	logger.reportError(e.name, fmt.Errorf(
+		"disabled after %d consecutive errors, next probe in %s", n, d))
+	logger.handleErrors()
+	time.AfterFunc(d, func() { logger.reprobeOutput(e) })
+}
+
+// The reprobeOutput enables the output disabled by the errors,
+// the next write probes the writer: if it fails, the output is
+// disabled again.
+func (logger *Logger) reprobeOutput(e *outputErrors) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	defer e.disabled.Store(false)
+	o, ok := logger.outputs[e.name]
+	if !ok || o.errs != e || o.Enabled.IsTrue() {
+		return
+	}
+
+	enabled := *o
+	enabled.Enabled = trit.True
+	logger.outputs[e.name] = &enabled
+}
+
+// The writeData writes the data to the writer,
+// the short write is reported as an error.
+func writeData(w io.Writer, data []byte) error {
+	n, err := w.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+
+	return err
+}
@@ -0,0 +1,243 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// The failWriter is the writer that fails while the fail flag is set.
+type failWriter struct {
+	fail   atomic.Bool
+	short  bool // the writer writes half of the data without an error
+	writes atomic.Int64
+}
+
+// Write returns the error if the fail flag is set.
+func (w *failWriter) Write(p []byte) (int, error) {
+	w.writes.Add(1)
+	switch {
+	case w.fail.Load():
+		return 0, errors.New("no space left on device")
+	case w.short:
+		return len(p) / 2, nil
+	}
+
+	return len(p), nil
+}
+
+// The errorRecorder records the errors passed to the handler.
+type errorRecorder struct {
+	errs []string
+	mu   sync.Mutex
+}
+
+// The handle is the ErrorHandler.
+func (r *errorRecorder) handle(output string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, output+": "+err.Error())
+}
+
+// The list returns the recorded errors.
+func (r *errorRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errs...)
+}
+
+// TestErrorHandler tests the reporting of the errors of the outputs.
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		writer   *failWriter
+		async    trit.Trit
+		expected string
+	}{
+		{
+			name:     "Write error",
+			writer:   &failWriter{},
+			expected: "test: no space left on device",
+		},
+		{
+			name:     "Short write",
+			writer:   &failWriter{short: true},
+			expected: "test: " + io.ErrShortWrite.Error(),
+		},
+		{
+			name:     "Asynchronous output",
+			writer:   &failWriter{},
+			async:    trit.True,
+			expected: "test: no space left on device",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &errorRecorder{}
+			logger := New()
+			logger.SetErrorHandler(rec.handle)
+			logger.SetOutputs(Output{
+				Name:   "test",
+				Writer: test.writer,
+				Levels: level.Default,
+				Async:  test.async,
+			})
+
+			test.writer.fail.Store(!test.writer.short)
+			for i := 0; i < 3; i++ {
+				logger.Info("message")
+			}
+			logger.Flush()
+
+			errs := rec.list()
+			if len(errs) != 3 || errs[0] != test.expected {
+				t.Fatalf("incorrect errors: %v", errs)
+			}
+
+			o := logger.Outputs("test")[0]
+			if o.Errors() != 3 || o.ConsecutiveErrors() != 3 {
+				t.Errorf("incorrect counters: %d %d",
+					o.Errors(), o.ConsecutiveErrors())
+			}
+
+			// The successful write resets the consecutive errors.
+			test.writer.fail.Store(false)
+			test.writer.short = false
+			logger.Info("message")
+			logger.Flush()
+
+			o = logger.Outputs("test")[0]
+			if o.Errors() != 3 || o.ConsecutiveErrors() != 0 {
+				t.Errorf("incorrect counters: %d %d",
+					o.Errors(), o.ConsecutiveErrors())
+			}
+		})
+	}
+}
+
+// TestErrorHandlerFormatter tests the errors of the formatter.
+func TestErrorHandlerFormatter(t *testing.T) {
+	rec := &errorRecorder{}
+	logger := New()
+	logger.SetErrorHandler(rec.handle)
+	logger.SetOutputs(Output{
+		Name:      "test",
+		Writer:    &bytes.Buffer{},
+		Levels:    level.Default,
+		Formatter: upperFormatter{},
+	})
+
+	logger.Info("") // the upperFormatter fails for the empty message
+	if errs := rec.list(); len(errs) != 1 ||
+		errs[0] != "test: empty message" {
+		t.Errorf("incorrect errors: %v", errs)
+	}
+
+	// The errors of the formatter are not the write errors.
+	o := logger.Outputs("test")[0]
+	if o.Errors() != 1 || o.ConsecutiveErrors() != 0 {
+		t.Errorf("incorrect counters: %d %d",
+			o.Errors(), o.ConsecutiveErrors())
+	}
+}
+
+// TestErrorHandlerLogger tests that the handler can use the logger:
+// it's called after the logger's lock is released.
+func TestErrorHandlerLogger(t *testing.T) {
+	buf, w := &bytes.Buffer{}, &failWriter{}
+	w.fail.Store(true)
+
+	logger := New()
+	logger.SetOutputs(
+		Output{Name: "console", Writer: buf, Levels: level.Default},
+		Output{Name: "broken", Writer: w, Levels: level.Error},
+	)
+	logger.SetErrorHandler(func(output string, err error) {
+		logger.EditOutputs(Output{Name: output, Enabled: trit.False})
+		logger.Warnf("output %s disabled: %v", output, err)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Error("failed")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock")
+	}
+
+	if o := logger.Outputs("broken")[0]; o.Enabled.IsTrue() {
+		t.Error("the output was not disabled")
+	}
+
+	want := "output broken disabled: no space left on device"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in %s", want, buf)
+	}
+}
+
+// TestOutputDisableAfter tests the auto-disabling of the output.
+func TestOutputDisableAfter(t *testing.T) {
+	rec := &errorRecorder{}
+	w := &failWriter{}
+	logger := New()
+	logger.SetErrorHandler(rec.handle)
+	logger.SetOutputs(Output{
+		Name:         "test",
+		Writer:       w,
+		Levels:       level.Default,
+		DisableAfter: 3,
+		ReprobeAfter: 50 * time.Millisecond,
+	})
+
+	enabled := func() bool {
+		return logger.Outputs("test")[0].Enabled.IsTrue()
+	}
+
+	w.fail.Store(true)
+	for i := 0; i < 3; i++ {
+		logger.Info("message")
+	}
+
+	waitFor(t, func() bool { return !enabled() })
+	logger.Info("message") // not written
+	if n := w.writes.Load(); n != 3 {
+		t.Errorf("expected 3 writes, got %d", n)
+	}
+
+	errs := rec.list()
+	if len(errs) != 4 || !strings.Contains(errs[3],
+		"disabled after 3 consecutive errors, next probe in 50ms") {
+		t.Fatalf("incorrect errors: %v", errs)
+	}
+
+	// The failed probe disables the output for the doubled interval.
+	waitFor(t, enabled)
+	logger.Info("message")
+	waitFor(t, func() bool { return !enabled() })
+	waitFor(t, func() bool {
+		errs := rec.list()
+		return strings.Contains(errs[len(errs)-1], "next probe in 100ms")
+	})
+
+	// The successful probe leaves the output enabled.
+	w.fail.Store(false)
+	waitFor(t, enabled)
+	logger.Info("message")
+	if o := logger.Outputs("test")[0]; o.ConsecutiveErrors() != 0 ||
+		!o.Enabled.IsTrue() {
+		t.Errorf("the output is not recovered")
+	}
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// The escalation is the temporary change of the levels of the output.
+type escalation struct {
+	levels level.Level
+	timer  *time.Timer
+}
+
+// The escalations is the list of the active escalations of the output.
+type escalations struct {
+	base   level.Level   // levels of the output before the escalations
+	active []*escalation // active escalations in order of start
+}
+
+// Escalate temporarily sets the levels of the default
+// logger's output, see Logger.Escalate.
+func Escalate(
+	name string,
+	levels level.Level,
+	d time.Duration,
+) (func(), error) {
+	return self.Escalate(name, levels, d)
+}
+
+// Escalate sets the levels of the output for the duration, then
+// restores the previous levels. It returns the function that cancels
+// the escalation before the duration expires, the function can be
+// called several times.
+//
+// The escalations of the same output can overlap: the levels of the
+// latest active escalation are used, and when all the escalations
+// end, the levels the output had before the first of them are restored
+// exactly. Only the levels are restored, other parameters of the output
+// changed during the escalation are kept.
+//
+// The start and the end of the escalation are logged at the Info level.
+//
+// Example usage:
+//
+//	cancel, err := logger.Escalate("stdout", level.Default, 15*time.Minute)
+//	if err != nil {
+//	    return err
+//	}
+//	defer cancel() // or let it expire
+func (logger *Logger) Escalate(
+	name string,
+	levels level.Level,
+	d time.Duration,
+) (func(), error) {
+	switch {
+	case levels == 0 || !levels.IsValid():
+		return nil, fmt.Errorf("incorrect levels %d", uint8(levels))
+	case d <= 0:
+		return nil, fmt.Errorf("incorrect duration %s", d)
+	}
+
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.Escalate(name, levels, d)
+	}
+
+	logger.tmu.Lock()
+	defer logger.tmu.Unlock()
+
+	outputs := logger.Outputs(name)
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("output not found '%s'", name)
+	}
+
+	if err := logger.EditOutputs(Output{Name: name, Levels: levels}); err != nil {
+		return nil, err
+	}
+
+	if logger.escalations == nil {
+		logger.escalations = make(map[string]*escalations)
+	}
+
+	state, ok := logger.escalations[name]
+	if !ok {
+		state = &escalations{base: outputs[0].Levels}
+		logger.escalations[name] = state
+	}
+
+	e := &escalation{levels: levels}
+	state.active = append(state.active, e)
+	e.timer = time.AfterFunc(d, func() { logger.deescalate(name, e) })
+
+	logger.Infof("log: output %s escalated to %s for %s\n", name, levels, d)
+	return func() { logger.deescalate(name, e) }, nil
+}
+
+// The deescalate ends the escalation of the output, if it's
+// active, and sets the levels of the remaining escalations
+// or the levels before the escalations.
+func (logger *Logger) deescalate(name string, e *escalation) {
+	logger.tmu.Lock()
+	defer logger.tmu.Unlock()
+
+	state, ok := logger.escalations[name]
+	if !ok {
+		return
+	}
+
+	i := -1
+	for j, v := range state.active {
+		if v == e {
+			i = j
+			break
+		}
+	}
+
+	if i < 0 {
+		return
+	}
+
+	e.timer.Stop()
+	state.active = append(state.active[:i], state.active[i+1:]...)
+
+	levels := state.base
+	if n := len(state.active); n != 0 {
+		levels = state.active[n-1].levels
+	} else {
+		delete(logger.escalations, name)
+	}
+
+	// The output can be deleted during the escalation.
+	if err := logger.EditOutputs(Output{Name: name, Levels: levels}); err != nil {
+		return
+	}
+
+	logger.Infof("log: escalation of the output %s ended, levels: %s\n",
+		name, levels)
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// TestEscalate tests the overlapping escalations.
+func TestEscalate(t *testing.T) {
+	base := level.Error | level.Info
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "app",
+		Writer: &bytes.Buffer{},
+		Levels: base,
+	})
+
+	levels := func() level.Level {
+		return logger.Outputs("app")[0].Levels
+	}
+
+	d := 100 * time.Millisecond
+	_, err := logger.Escalate("app", level.AtMost(level.Debug), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel, err := logger.Escalate("app", level.Default, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if levels() != level.Default {
+		t.Fatalf("expected all levels, got %s", levels())
+	}
+
+	// Other parameters changed during the escalation are kept.
+	logger.EditOutputs(Output{Name: "app", Enabled: -1})
+
+	// The latest escalation is canceled, the first one is active.
+	cancel()
+	cancel()
+	if levels() != level.AtMost(level.Debug) {
+		t.Fatalf("expected debug|trace, got %s", levels())
+	}
+
+	// The first escalation expires, the exact mask is restored.
+	waitFor(t, func() bool { return levels() == base })
+	if o := logger.Outputs("app")[0]; !o.Enabled.IsFalse() {
+		t.Errorf("the output was enabled")
+	}
+
+	if len(logger.escalations) != 0 {
+		t.Errorf("the escalations were not removed: %v", logger.escalations)
+	}
+}
+
+// TestEscalateErrors tests the incorrect escalations.
+func TestEscalateErrors(t *testing.T) {
+	logger := New()
+	tests := []struct {
+		name   string
+		output string
+		levels level.Level
+		d      time.Duration
+	}{
+		{"Unknown output", "missing", level.Debug, time.Second},
+		{"Zero levels", "stdout", 0, time.Second},
+		{"Zero duration", "stdout", level.Debug, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logger.Escalate(tt.output, tt.levels, tt.d)
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// The deleted output.
+	cancel, err := logger.Escalate("stdout", level.Trace, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.DeleteOutputs("stdout")
+	cancel()
+	if len(logger.Outputs()) != 1 {
+		t.Errorf("the output was restored: %v", logger.Outputs())
+	}
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FallbackField is the key of the field that is added to the record
+// written to the fallback output, its value is the name of the output
+// that failed to write the record, see Output.Fallback.
+const FallbackField = "failed_output"
+
+// The fallback writes the record that the output failed to write to
+// the fallback output of the output, if it's specified.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) fallback(name string, o *Output, r *Record) {
+	next := o.Fallback
+	if next == "" {
+		return
+	}
+
+	// The outputs are checked by SetOutputs and EditOutputs, but
+	// the record can't be passed to the output that already failed.
+	if next == name || slices.Contains(r.failed, next) {
+		logger.reportError(name, fmt.Errorf("fallback loop at '%s'", next))
+		return
+	}
+
+	fb, ok := logger.outputs[next]
+	if !ok {
+		logger.reportError(name,
+			fmt.Errorf("fallback output not found '%s'", next))
+		return
+	}
+
+	// The copy of the record is marked by the name of the output
+	// that failed first, the fields of the record are not changed.
+	fr := *r
+	fr.failed = append(slices.Clip(r.failed), name)
+	fr.Fields = mergeFields(r.Fields,
+		[]Field{{Key: FallbackField, Value: fr.failed[0]}})
+
+	// The disabled fallback output passes the record further,
+	// the record is dropped if there is no further output.
+	if !fb.Enabled.IsTrue() {
+		if fb.Fallback == "" {
+			logger.reportError(name, fmt.Errorf(
+				"fallback output '%s' is disabled, record dropped", next))
+			return
+		}
+
+		logger.fallback(next, fb, &fr)
+		return
+	}
+
+	logger.write(next, fb, &fr)
+}
+
+// The asyncFallback writes the record that the asynchronous output
+// failed to write to the fallback output, see fallback.
+func (logger *Logger) asyncFallback(name string, r *Record) {
+	defer logger.handleErrors()
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	if o, ok := logger.outputs[name]; ok && !logger.closed {
+		logger.fallback(name, o, r)
+	}
+}
+
+// The checkFallbacks returns an error if the fallback output
+// of an output is not found, or the fallbacks form a loop.
+func checkFallbacks(outputs map[string]*Output) error {
+	for name, o := range outputs {
+		seen := map[string]bool{name: true}
+		for next := o.Fallback; next != ""; {
+			fb, ok := outputs[next]
+			switch {
+			case !ok:
+				return fmt.Errorf("fallback output not found '%s'", next)
+			case seen[next]:
+				return fmt.Errorf("fallback loop of the output '%s'", name)
+			}
+
+			seen[next] = true
+			next = fb.Fallback
+		}
+	}
+
+	return nil
+}
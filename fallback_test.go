@@ -0,0 +1,168 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// TestOutputFallback tests the fallback outputs.
+func TestOutputFallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		async  trit.Trit
+		spare  trit.Trit // the first fallback output is enabled
+		errors int
+	}{
+		{name: "Synchronous output", errors: 2},
+		{name: "Asynchronous output", async: trit.True, errors: 2},
+		{name: "Disabled fallback output", spare: trit.False, errors: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := &failWriter{}
+			file.fail.Store(true)
+			spare := &failWriter{}
+			spare.fail.Store(true)
+			buf := &syncBuffer{}
+
+			rec := &errorRecorder{}
+			logger := New()
+			logger.SetErrorHandler(rec.handle)
+			err := logger.SetOutputs(
+				Output{
+					Name:     "file",
+					Writer:   file,
+					Levels:   level.Default,
+					Async:    test.async,
+					Fallback: "spare",
+				},
+				Output{
+					Name:     "spare",
+					Writer:   spare,
+					Levels:   level.Debug, // the levels are ignored
+					Enabled:  test.spare,
+					Fallback: "console",
+				},
+				Output{
+					Name:   "console",
+					Writer: buf,
+					Levels: level.Debug, // the levels are ignored
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The Flush waits for the fallbacks of the asynchronous output.
+			logger.Errorln("disk full", "id", 7)
+			logger.Flush()
+
+			out := buf.String()
+			if strings.Count(out, "disk full") != 1 ||
+				!strings.Contains(out, FallbackField+"=file") {
+				t.Errorf("incorrect output: %s", out)
+			}
+
+			if errs := rec.list(); len(errs) != test.errors {
+				t.Errorf("incorrect errors: %v", errs)
+			}
+		})
+	}
+}
+
+// TestOutputFallbackDropped tests that the record is reported
+// as dropped if the last fallback output is disabled.
+func TestOutputFallbackDropped(t *testing.T) {
+	file := &failWriter{}
+	file.fail.Store(true)
+
+	rec := &errorRecorder{}
+	logger := New()
+	logger.SetErrorHandler(rec.handle)
+	logger.SetOutputs(
+		Output{Name: "file", Writer: file, Fallback: "spare"},
+		Output{Name: "spare", Writer: &syncBuffer{}, Enabled: trit.False},
+	)
+
+	logger.Error("disk full")
+	errs := rec.list()
+	if len(errs) != 2 || errs[1] !=
+		"file: fallback output 'spare' is disabled, record dropped" {
+		t.Errorf("incorrect errors: %v", errs)
+	}
+}
+
+// TestCheckFallbacks tests the validation of the fallback outputs.
+func TestCheckFallbacks(t *testing.T) {
+	buf := &syncBuffer{}
+	tests := []struct {
+		name    string
+		outputs []Output
+		err     string
+	}{
+		{
+			name: "Correct chain",
+			outputs: []Output{
+				{Name: "a", Writer: buf, Fallback: "b"},
+				{Name: "b", Writer: buf, Fallback: "c"},
+				{Name: "c", Writer: buf},
+			},
+		},
+		{
+			name: "Unknown output",
+			outputs: []Output{
+				{Name: "a", Writer: buf, Fallback: "b"},
+			},
+			err: "fallback output not found 'b'",
+		},
+		{
+			name: "Self loop",
+			outputs: []Output{
+				{Name: "a", Writer: buf, Fallback: "a"},
+			},
+			err: "fallback loop",
+		},
+		{
+			name: "Loop",
+			outputs: []Output{
+				{Name: "a", Writer: buf, Fallback: "b"},
+				{Name: "b", Writer: buf, Fallback: "c"},
+				{Name: "c", Writer: buf, Fallback: "a"},
+			},
+			err: "fallback loop",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := New()
+			err := logger.SetOutputs(test.outputs...)
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.err != "" &&
+				(err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+
+	// The edit that creates the loop is rejected.
+	logger := New()
+	logger.SetOutputs(
+		Output{Name: "a", Writer: buf, Fallback: "b"},
+		Output{Name: "b", Writer: buf},
+	)
+
+	if err := logger.EditOutputs(Output{Name: "b", Fallback: "a"}); err == nil {
+		t.Error("expected error for the loop")
+	}
+
+	if o := logger.Outputs("b")[0]; o.Fallback != "" {
+		t.Errorf("the output is changed: %q", o.Fallback)
+	}
+}
@@ -0,0 +1,188 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The badKey is the key used for values that were passed to With or
+// to the *w methods without a valid string key, e.g. With(42) or
+// With("user_id", 7, "dangling").
+const badKey = "!BADKEY"
+
+// The reservedKeys are the keys used by the JSON style of the message.
+// A field with one of these keys is moved to the "fields." namespace so
+// that it cannot overwrite the level, the message etc.
+var reservedKeys = map[string]bool{
+	"prefix":      true,
+	"level":       true,
+	"timestamp":   true,
+	"message":     true,
+	"filePath":    true,
+	"lineNumber":  true,
+	"funcName":    true,
+	"funcAddress": true,
+}
+
+// Field is a key/value pair attached to the log-message.
+type Field struct {
+	Key   string // field name
+	Value any    // field value
+}
+
+// The makeFields converts a list of alternating keys and values into
+// a list of fields. A key must be a string; when a key is not a string
+// or the value is missing, the element is stored under the badKey.
+func makeFields(kv ...any) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+
+	result := make([]Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i++ {
+		key, ok := kv[i].(string)
+		if !ok || i == len(kv)-1 {
+			result = append(result, Field{Key: badKey, Value: kv[i]})
+			continue
+		}
+
+		result = append(result, Field{Key: key, Value: kv[i+1]})
+		i++
+	}
+
+	return result
+}
+
+// The mergeFields returns a new list of fields that contains the fields
+// of the base list updated by the fields of the extra list. The order of
+// the keys is preserved: the value of an existing key is replaced in
+// place, new keys are appended to the end.
+func mergeFields(base, extra []Field) []Field {
+	switch {
+	case len(extra) == 0:
+		return base
+	case len(base) == 0:
+		return extra
+	}
+
+	result := make([]Field, len(base), len(base)+len(extra))
+	copy(result, base)
+
+	for _, f := range extra {
+		replaced := false
+		for i := range result {
+			if result[i].Key == f.Key && f.Key != badKey {
+				result[i].Value = f.Value
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// The fieldKeys returns the keys of the fields.
+func fieldKeys(fields []Field) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+
+	return keys
+}
+
+// The fieldValue returns the value of the field prepared for
+// the serialization, e.g. errors are converted to their text.
+func fieldValue(v any) any {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+
+	return v
+}
+
+// The needsQuoting returns true if the text value of the field
+// must be quoted in the text style of the message.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The textFields renders fields as key=value pairs separated by space.
+// Values containing spaces, quotes or special characters are quoted.
+func textFields(space string, fields []Field) string {
+	sb := strings.Builder{}
+	for i, f := range fields {
+		if i != 0 {
+			sb.WriteString(space)
+		}
+
+		v := fmt.Sprint(fieldValue(f.Value))
+		if needsQuoting(v) {
+			v = strconv.Quote(v)
+		}
+
+		sb.WriteString(f.Key)
+		sb.WriteString("=")
+		sb.WriteString(v)
+	}
+
+	return sb.String()
+}
+
+// The objectFields appends fields as top-level keys to the JSON object.
+// The data must be a valid marshaled JSON object.
+func objectFields(data []byte, fields []Field) []byte {
+	if len(fields) == 0 || len(data) < 2 {
+		return data
+	}
+
+	buf := make([]byte, 0, len(data)+len(fields)*16)
+	buf = append(buf, data[:len(data)-1]...)
+	empty := len(data) == 2 // {}
+
+	for _, f := range fields {
+		key := f.Key
+		if reservedKeys[key] {
+			key = "fields." + key
+		}
+
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(fieldValue(f.Value))
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(f.Value))
+		}
+
+		if !empty {
+			buf = append(buf, ',')
+		}
+		empty = false
+
+		buf = append(buf, k...)
+		buf = append(buf, ':')
+		buf = append(buf, v...)
+	}
+
+	return append(buf, '}')
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestMakeFields tests makeFields function.
+func TestMakeFields(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []any
+		want []Field
+	}{
+		{
+			name: "Empty list",
+			in:   []any{},
+			want: nil,
+		},
+		{
+			name: "Key/value pairs",
+			in:   []any{"user_id", 7, "ok", true},
+			want: []Field{{"user_id", 7}, {"ok", true}},
+		},
+		{
+			name: "Not a string key",
+			in:   []any{42, "user_id", 7},
+			want: []Field{{badKey, 42}, {"user_id", 7}},
+		},
+		{
+			name: "Key without value",
+			in:   []any{"user_id", 7, "dangling"},
+			want: []Field{{"user_id", 7}, {badKey, "dangling"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := makeFields(tt.in...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+// TestMergeFields tests mergeFields function.
+func TestMergeFields(t *testing.T) {
+	base := []Field{{"a", 1}, {"b", 2}}
+	extra := []Field{{"b", 3}, {"c", 4}}
+
+	got := mergeFields(base, extra)
+	want := []Field{{"a", 1}, {"b", 3}, {"c", 4}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], got[i])
+		}
+	}
+
+	// The base list must not be changed.
+	if base[1].Value != 2 {
+		t.Errorf("the base list was changed: %v", base)
+	}
+}
+
+// TestTextFields tests textFields function.
+func TestTextFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		want   string
+	}{
+		{
+			name:   "Simple values",
+			fields: []Field{{"user_id", 7}, {"ok", true}},
+			want:   "user_id=7 ok=true",
+		},
+		{
+			name:   "Value with spaces",
+			fields: []Field{{"name", "John Doe"}},
+			want:   `name="John Doe"`,
+		},
+		{
+			name:   "Empty value",
+			fields: []Field{{"name", ""}},
+			want:   `name=""`,
+		},
+		{
+			name:   "Error value",
+			fields: []Field{{"err", errors.New("not found")}},
+			want:   `err="not found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textFields(" ", tt.fields); got != tt.want {
+				t.Errorf("expected `%s`, got `%s`", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestObjectFields tests objectFields function.
+func TestObjectFields(t *testing.T) {
+	data := objectFields([]byte(`{"level":"INFO"}`), []Field{
+		{"user_id", 7},
+		{"err", errors.New("not found")},
+		{"level", "custom"},
+	})
+
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+
+	if obj["level"] != "INFO" {
+		t.Errorf("the level was overwritten: %s", data)
+	}
+
+	if obj["fields.level"] != "custom" {
+		t.Errorf("the reserved key was not moved: %s", data)
+	}
+
+	if obj["user_id"] != float64(7) || obj["err"] != "not found" {
+		t.Errorf("incorrect fields: %s", data)
+	}
+
+	// Empty object.
+	data = objectFields([]byte(`{}`), []Field{{"a", 1}})
+	if string(data) != `{"a":1}` {
+		t.Errorf("expected `{\"a\":1}`, got `%s`", data)
+	}
+}
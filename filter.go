@@ -0,0 +1,125 @@
+package log
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/goloop/log/level"
+)
+
+// Filter is the rule that selects the levels of the records of the
+// output by the place in the code where the record was logged. The
+// rule matches the record if all its specified conditions match, the
+// empty rule matches all records.
+//
+// Example usage:
+//
+//	// Debug for the billing packages, Info and above for the rest.
+//	logger.SetOutputs(log.Output{
+//	    Name:   "stdout",
+//	    Writer: os.Stdout,
+//	    Levels: level.AtLeast(level.Info),
+//	    Filters: []log.Filter{
+//	        {
+//	            Package: "internal/billing/...",
+//	            Levels:  level.AtLeast(level.Debug),
+//	        },
+//	    },
+//	})
+type Filter struct {
+	// File is the glob pattern (see path.Match) of the path to the
+	// file, it's matched against the same number of the last sections
+	// of the path, e.g. "billing/*.go" or "*_handler.go". The pattern
+	// that starts with "/" is matched against the full path.
+	File string
+
+	// Package is the path of the package, it's matched against the
+	// end of the import path, e.g. "internal/billing" matches the
+	// "github.com/acme/app/internal/billing" package. The "/..."
+	// suffix matches the subpackages too: "internal/billing/...".
+	Package string
+
+	// Func is the regular expression that is matched against the
+	// full name of the function with the import path of its package,
+	// e.g. "github.com/acme/app/billing.(*Service).Charge". It's not
+	// anchored: `\.Charge$` matches the method above.
+	Func *regexp.Regexp
+
+	// Levels is the set of the levels of the records that
+	// match the rule, it replaces the levels of the output.
+	Levels level.Level
+}
+
+// The match returns true if the rule matches the record.
+func (f *Filter) match(r *Record) bool {
+	if f.File != "" && !matchFile(f.File, r.FilePath) {
+		return false
+	}
+
+	if f.Package != "" && !matchPackage(f.Package, funcPackage(r.function)) {
+		return false
+	}
+
+	if f.Func != nil && !f.Func.MatchString(r.function) {
+		return false
+	}
+
+	return true
+}
+
+// The levels returns the levels of the output for the record:
+// the levels of the first filter that matches the record, or
+// the levels of the output if there is no such filter.
+func (o *Output) levels(r *Record) level.Level {
+	for i := range o.Filters {
+		if o.Filters[i].match(r) {
+			return o.Filters[i].Levels
+		}
+	}
+
+	return o.Levels
+}
+
+// The matchFile returns true if the path to the file matches the glob
+// pattern: the pattern is matched against the same number of the last
+// sections of the path, or the full path for the absolute pattern.
+func matchFile(pattern, file string) bool {
+	if !strings.HasPrefix(pattern, "/") {
+		n := strings.Count(pattern, "/") + 1
+		sections := strings.Split(file, "/")
+		if len(sections) > n {
+			file = strings.Join(sections[len(sections)-n:], "/")
+		}
+	}
+
+	ok, err := path.Match(pattern, file)
+	return ok && err == nil
+}
+
+// The matchPackage returns true if the import path of the package
+// matches the pattern, see Filter.Package.
+func matchPackage(pattern, pkg string) bool {
+	base, recursive := strings.CutSuffix(pattern, "/...")
+	if pkg == base || strings.HasSuffix(pkg, "/"+base) {
+		return true
+	}
+
+	if !recursive {
+		return false
+	}
+
+	return strings.HasPrefix(pkg, base+"/") ||
+		strings.Contains(pkg, "/"+base+"/")
+}
+
+// The funcPackage returns the import path of the package
+// of the function by the full name of the function.
+func funcPackage(name string) string {
+	i := strings.LastIndex(name, "/")
+	if j := strings.Index(name[i+1:], "."); j >= 0 {
+		return name[:i+1+j]
+	}
+
+	return name
+}
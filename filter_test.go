@@ -0,0 +1,166 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+)
+
+// TestMatchFile tests matchFile function.
+func TestMatchFile(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		file     string
+		expected bool
+	}{
+		{"*.go", "/src/app/internal/billing/charge.go", true},
+		{"charge.go", "/src/app/internal/billing/charge.go", true},
+		{"billing/*.go", "/src/app/internal/billing/charge.go", true},
+		{"billing/*.go", "/src/app/internal/billing/v2/charge.go", false},
+		{"*_test.go", "/src/app/internal/billing/charge.go", false},
+		{"/src/app/*/billing/*.go", "/src/app/internal/billing/charge.go", true},
+		{"/app/*/billing/*.go", "/src/app/internal/billing/charge.go", false},
+		{"[", "/src/app/main.go", false}, // bad pattern
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			got := matchFile(test.pattern, test.file)
+			if got != test.expected {
+				t.Errorf("matchFile(%q, %q) = %v, expected %v",
+					test.pattern, test.file, got, test.expected)
+			}
+		})
+	}
+}
+
+// TestMatchPackage tests matchPackage function.
+func TestMatchPackage(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		pkg      string
+		expected bool
+	}{
+		{"internal/billing", "example.com/app/internal/billing", true},
+		{"internal/billing", "example.com/app/internal/billing/v2", false},
+		{"internal/billing", "example.com/app/internal/xbilling", false},
+		{"internal/billing/...", "example.com/app/internal/billing", true},
+		{"internal/billing/...", "example.com/app/internal/billing/v2", true},
+		{"internal/billing/...", "example.com/app/internal/billingx", false},
+		{"example.com/app/...", "example.com/app/internal/billing", true},
+		{"main", "main", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			got := matchPackage(test.pattern, test.pkg)
+			if got != test.expected {
+				t.Errorf("matchPackage(%q, %q) = %v, expected %v",
+					test.pattern, test.pkg, got, test.expected)
+			}
+		})
+	}
+}
+
+// TestFuncPackage tests funcPackage function.
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"main.main", "main"},
+		{"example.com/app/billing.Charge", "example.com/app/billing"},
+		{"example.com/app/billing.(*Service).Charge", "example.com/app/billing"},
+		{"example.com/app/billing.Charge.func1", "example.com/app/billing"},
+		{"example.com/app.v2/billing.Charge", "example.com/app.v2/billing"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := funcPackage(test.name); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestOutputFilters tests the filters of the output.
+func TestOutputFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool // the debug record is written
+	}{
+		{
+			name:     "No filters",
+			expected: false,
+		},
+		{
+			name:     "Package",
+			filter:   Filter{Package: "goloop/log", Levels: level.Default},
+			expected: true,
+		},
+		{
+			name:     "Other package",
+			filter:   Filter{Package: "goloop/log/level", Levels: level.Default},
+			expected: false,
+		},
+		{
+			name:     "File",
+			filter:   Filter{File: "filter_*.go", Levels: level.Default},
+			expected: true,
+		},
+		{
+			name: "Func",
+			filter: Filter{
+				Func:   regexp.MustCompile(`\.TestOutputFilters\.func\d+$`),
+				Levels: level.Default,
+			},
+			expected: true,
+		},
+		{
+			name: "All conditions",
+			filter: Filter{
+				File:    "filter_test.go",
+				Package: "log",
+				Func:    regexp.MustCompile(`Other`),
+				Levels:  level.Default,
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &syncBuffer{}
+			logger := New()
+			logger.SetOutputs(Output{
+				Name:   "test",
+				Writer: buf,
+				Levels: level.AtLeast(level.Info),
+			})
+
+			if test.filter != (Filter{}) {
+				logger.EditOutputs(Output{
+					Name:    "test",
+					Filters: []Filter{test.filter},
+				})
+			}
+
+			logger.Debugln("debug record")
+			logger.Infoln("info record")
+
+			out := buf.String()
+			if !strings.Contains(out, "info record") {
+				t.Errorf("the info record is not written: %s", out)
+			}
+
+			got := strings.Contains(out, "debug record")
+			if got != test.expected {
+				t.Errorf("expected %v, got %v: %s", test.expected, got, out)
+			}
+		})
+	}
+}
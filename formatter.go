@@ -0,0 +1,87 @@
+package log
+
+// Formatter is the interface of the renderers of the records. The
+// formatter of the output converts the record into the data written
+// to the writer of the output. The parameters of the output (Layouts,
+// Space, WithPrefix, TimestampFormat etc.) are passed to the formatter,
+// it can use or ignore them.
+//
+// Example usage:
+//
+//	type upperFormatter struct{}
+//
+//	func (upperFormatter) Format(r *log.Record, o *log.Output) ([]byte, error) {
+//	    return []byte(strings.ToUpper(r.Message) + "\n"), nil
+//	}
+//
+//	logger.SetOutputs(log.Output{
+//	    Name:      "upper",
+//	    Writer:    os.Stdout,
+//	    Formatter: upperFormatter{},
+//	})
+type Formatter interface {
+	Format(r *Record, o *Output) ([]byte, error)
+}
+
+// TextFormatter is the built-in formatter of the text style:
+// the header (prefix, timestamp, level, caller and the fields
+// of the context) followed by the message and the other fields
+// as key=value pairs.
+type TextFormatter struct{}
+
+// Format renders the record as a text message.
+func (TextFormatter) Format(r *Record, o *Output) ([]byte, error) {
+	f, a := r.args()
+	header, fields := r.splitFields()
+	msg := textMessage(
+		r.prefix(o),
+		r.Level,
+		r.Time,
+		o,
+		r.stackFrame(),
+		header,
+		fields,
+		f,
+		a...,
+	)
+
+	return []byte(msg), nil
+}
+
+// JSONFormatter is the built-in formatter of the JSON style: the
+// record is rendered as a JSON object, the fields are its top-level
+// keys.
+type JSONFormatter struct{}
+
+// Format renders the record as a JSON object.
+func (JSONFormatter) Format(r *Record, o *Output) ([]byte, error) {
+	f, a := r.args()
+	msg, err := objectMessage(
+		r.prefix(o),
+		r.Level,
+		r.Time,
+		o,
+		r.stackFrame(),
+		r.Fields,
+		f,
+		a...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(msg), nil
+}
+
+// The formatter returns the formatter of the output. If the Formatter
+// is not specified, the built-in formatter is selected by TextStyle.
+func (o *Output) formatter() Formatter {
+	switch {
+	case o.Formatter != nil:
+		return o.Formatter
+	case o.TextStyle.IsTrue():
+		return TextFormatter{}
+	}
+
+	return JSONFormatter{}
+}
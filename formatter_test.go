@@ -0,0 +1,157 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// The upperFormatter is the custom formatter for tests.
+type upperFormatter struct{}
+
+// Format renders the level and the message in upper case.
+func (upperFormatter) Format(r *Record, o *Output) ([]byte, error) {
+	if r.Message == "" {
+		return nil, errors.New("empty message")
+	}
+
+	msg := level.Labels[r.Level] + ":" + strings.ToUpper(r.Message)
+	return []byte(msg + "\n"), nil
+}
+
+// TestFormatter tests the Formatter of the output.
+func TestFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New("APP")
+	logger.SetOutputs(Output{
+		Name:      "upper",
+		Writer:    buf,
+		Levels:    level.Default,
+		Formatter: upperFormatter{},
+	})
+
+	logger.Infof("hello %s", "world")
+	logger.Info("") // formatter error, nothing is written
+	if got := buf.String(); got != "INFO:HELLO WORLD\n" {
+		t.Errorf("incorrect message: %q", got)
+	}
+
+	// The built-in formatter replaces the custom one.
+	buf.Reset()
+	logger.EditOutputs(Output{Name: "upper", Formatter: JSONFormatter{}})
+	logger.Infoln("json")
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if obj["message"] != "json" || obj["prefix"] != "APP" {
+		t.Errorf("incorrect JSON message: %s", buf.String())
+	}
+}
+
+// TestFormatterTextStyle tests that the built-in formatters
+// render the same messages as the TextStyle.
+func TestFormatterTextStyle(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     trit.Trit
+		formatter Formatter
+	}{
+		{"Text", trit.True, TextFormatter{}},
+		{"JSON", trit.False, JSONFormatter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style, formatter := &bytes.Buffer{}, &bytes.Buffer{}
+			logger := New("APP")
+			logger.SetOutputs(
+				Output{
+					Name:      "style",
+					Writer:    style,
+					Levels:    level.Default,
+					TextStyle: tt.style,
+				},
+				Output{
+					Name:      "formatter",
+					Writer:    formatter,
+					Levels:    level.Default,
+					Formatter: tt.formatter,
+				},
+			)
+
+			logger.With("user_id", 7).Warnf("slow request %d", 42)
+			if style.String() != formatter.String() {
+				t.Errorf("expected %q, got %q", style, formatter)
+			}
+		})
+	}
+}
+
+// The messageFormatter records the messages of the records.
+type messageFormatter struct {
+	messages *[]string
+}
+
+// Format records the message and renders nothing.
+func (f messageFormatter) Format(r *Record, o *Output) ([]byte, error) {
+	*f.messages = append(*f.messages, r.Message)
+	return nil, nil
+}
+
+// TestRecordNewline tests that the message of the record has no
+// trailing newline, and the text style still renders it as logged.
+func TestRecordNewline(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *Logger)
+		text string
+	}{
+		{"Print", func(l *Logger) { l.Info("msg") }, "msg"},
+		{"Println", func(l *Logger) { l.Infoln("msg") }, "msg\n"},
+		{"Printf", func(l *Logger) { l.Infof("%s", "msg") }, "msg"},
+		{"Printf with newline", func(l *Logger) {
+			l.Infof("%s\n", "msg")
+		}, "msg\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			buf := &bytes.Buffer{}
+			logger := New()
+			logger.SetOutputs(
+				Output{
+					Name:      "custom",
+					Writer:    &bytes.Buffer{},
+					Levels:    level.Default,
+					Formatter: messageFormatter{&messages},
+				},
+				Output{
+					Name:      "text",
+					Writer:    buf,
+					Layouts:   layout.LineNumber,
+					Levels:    level.Default,
+					TextStyle: trit.True,
+				},
+			)
+
+			tt.log(logger)
+			if len(messages) != 1 || messages[0] != "msg" {
+				t.Errorf("expected the msg message, got %q", messages)
+			}
+
+			if out := buf.String(); !strings.HasSuffix(out, " "+tt.text) {
+				t.Errorf("expected %q at the end of %q", tt.text, out)
+			}
+		})
+	}
+}
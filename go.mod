@@ -1,6 +1,6 @@
 module github.com/goloop/log
 
-go 1.20
+go 1.21
 
 require (
 	github.com/goloop/g v1.12.1
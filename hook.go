@@ -0,0 +1,185 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/goloop/log/level"
+)
+
+// ErrDropRecord is returned by Hook.Fire to veto the record:
+// the record is not written to the outputs and the remaining
+// hooks are not fired. This error is not reported.
+var ErrDropRecord = errors.New("log: record dropped by hook")
+
+// Hook is the handler that is fired for each record of the
+// specified levels before the record is written to the outputs.
+//
+// The Fire method can change the record (e.g. add fields), veto it by
+// returning ErrDropRecord, or run side effects such as incrementing
+// metrics. Other errors are passed to the hook error handler (see
+// Logger.SetHookErrorHandler) after the record is written.
+//
+// Note: the hooks are fired synchronously while the logger is locked
+// for reading, so a hook must not change the logger (AddHook,
+// SetOutputs etc.) and should not be slow.
+type Hook interface {
+	// Levels returns the levels of the records the hook is fired for,
+	// e.g. level.Error|level.Fatal.
+	Levels() level.Level
+
+	// Fire handles the record.
+	Fire(r *Record) error
+}
+
+// HookErrorHandler is the function that handles errors of the hooks.
+//
+// The handler is called after the logger's lock is released, so it
+// can log the error by the same logger. It must not block.
+type HookErrorHandler func(h Hook, r *Record, err error)
+
+// The hookError is the error of the hook that waits for the handler,
+// the handler is the handler of the logger that fired the hook.
+type hookError struct {
+	handler HookErrorHandler
+	hook    Hook
+	record  *Record
+	err     error
+}
+
+// The handle passes the error to the handler,
+// or writes it to os.Stderr if there is no handler.
+func (e hookError) handle() {
+	if e.handler != nil {
+		e.handler(e.hook, e.record, e.err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "log: hook %T: %v\n", e.hook, e.err)
+}
+
+// HookFunc returns the hook that calls the fn function
+// for records of the specified levels.
+//
+// Example usage:
+//
+//	logger.AddHook(log.HookFunc(level.Error, func(r *log.Record) error {
+//	    errorsTotal.Inc()
+//	    return nil
+//	}))
+func HookFunc(levels level.Level, fn func(r *Record) error) Hook {
+	return &funcHook{levels: levels, fn: fn}
+}
+
+// The funcHook is the hook created by HookFunc.
+type funcHook struct {
+	levels level.Level
+	fn     func(r *Record) error
+}
+
+// Levels returns the levels of the hook.
+func (h *funcHook) Levels() level.Level {
+	return h.levels
+}
+
+// Fire calls the function of the hook.
+func (h *funcHook) Fire(r *Record) error {
+	return h.fn(r)
+}
+
+// AddHook adds hooks to the default logger.
+func AddHook(hooks ...Hook) {
+	self.AddHook(hooks...)
+}
+
+// SetHookErrorHandler sets the handler of the errors
+// returned by the hooks of the default logger.
+func SetHookErrorHandler(h HookErrorHandler) {
+	self.SetHookErrorHandler(h)
+}
+
+// AddHook adds hooks to the logger. The hooks are fired in the order
+// they were added, once per record, before the record is written to
+// the outputs.
+//
+// Example usage:
+//
+//	logger.AddHook(log.HookFunc(level.Default, func(r *log.Record) error {
+//	    r.AddFields("host", hostname)
+//	    return nil
+//	}))
+func (logger *Logger) AddHook(hooks ...Hook) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	for _, h := range hooks {
+		if h != nil {
+			logger.hooks = append(logger.hooks, h)
+		}
+	}
+}
+
+// SetHookErrorHandler sets the handler of the errors returned by the
+// hooks. By default, the errors are written to os.Stderr. If h is nil,
+// the default handler is used.
+//
+// The handler is called after the logger's lock is released, so it may
+// log the error by the same logger, e.g.:
+//
+//	logger.SetHookErrorHandler(func(h log.Hook, r *log.Record, err error) {
+//	    logger.Warnf("hook %T failed: %v", h, err)
+//	})
+func (logger *Logger) SetHookErrorHandler(h HookErrorHandler) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.hookErrorHandler = h
+}
+
+// The fire fires the hooks for the record. It returns false if
+// the record was vetoed by a hook.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) fire(r *Record) bool {
+	cloned := false
+	for _, h := range logger.hooks {
+		levels := h.Levels()
+		if has, err := levels.Contains(r.Level); !has || err != nil {
+			continue
+		}
+
+		// The fields can be shared with the logger,
+		// the hook gets its own copy.
+		if !cloned {
+			r.render()
+			r.Fields = slices.Clone(r.Fields)
+			cloned = true
+		}
+
+		err := h.Fire(r)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDropRecord):
+			return false
+		default:
+			logger.base().reportHookError(hookError{
+				handler: logger.hookErrorHandler,
+				hook:    h,
+				record:  r,
+				err:     err,
+			})
+		}
+	}
+
+	return true
+}
+
+// The reportHookError reports the error of the hook, it's passed to
+// the handler by the handleErrors after the logger's lock is released.
+func (logger *Logger) reportHookError(e hookError) {
+	logger.emu.Lock()
+	logger.pendingHookErrors = append(logger.pendingHookErrors, e)
+	logger.hasErrors.Store(true)
+	logger.emu.Unlock()
+}
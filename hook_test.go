@@ -0,0 +1,139 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// TestHook tests enrichment and veto of the records by the hooks.
+func TestHook(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New("APP")
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+
+	errorsTotal := 0
+	logger.AddHook(
+		HookFunc(level.Error|level.Warn, func(r *Record) error {
+			errorsTotal++
+			r.AddFields("alert", true)
+			return nil
+		}),
+		HookFunc(level.Default, func(r *Record) error {
+			if strings.HasPrefix(r.Message, "secret") {
+				return ErrDropRecord
+			}
+
+			r.Message = strings.ToUpper(r.Message)
+			return nil
+		}),
+	)
+
+	child := logger.With("user_id", 7)
+	child.Infoln("accepted")
+	child.Errorf("failed %d", 42)
+	child.Warn("secret token")
+
+	out := buf.String()
+	if !strings.Contains(out, "ACCEPTED user_id=7\n") {
+		t.Errorf("the message was not changed: %s", out)
+	}
+
+	if !strings.Contains(out, "FAILED 42 user_id=7 alert=true") {
+		t.Errorf("the fields were not added: %s", out)
+	}
+
+	if strings.Contains(out, "SECRET") || strings.Contains(out, "secret") {
+		t.Errorf("the vetoed record was written: %s", out)
+	}
+
+	if errorsTotal != 2 {
+		t.Errorf("expected 2 calls, got %d", errorsTotal)
+	}
+
+	// The hook doesn't change the fields of the logger.
+	buf.Reset()
+	child.Infoln("next")
+	if strings.Contains(buf.String(), "alert") {
+		t.Errorf("the fields of the logger were changed: %s", buf)
+	}
+}
+
+// TestHookError tests the hook error handler.
+func TestHookError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+
+	errFailed := errors.New("webhook is unavailable")
+	logger.AddHook(HookFunc(level.Fatal|level.Error, func(*Record) error {
+		return errFailed
+	}))
+
+	var reported []error
+	logger.SetHookErrorHandler(func(h Hook, r *Record, err error) {
+		reported = append(reported, err)
+	})
+
+	logger.Info("skipped")
+	logger.Error("failed")
+	if len(reported) != 1 || reported[0] != errFailed {
+		t.Errorf("expected the hook error, got %v", reported)
+	}
+
+	// The record is written despite the hook error.
+	if !strings.Contains(buf.String(), "failed") {
+		t.Errorf("the record was not written: %s", buf)
+	}
+}
+
+// TestHookErrorLogger tests that the hook error handler is called
+// without the logger's lock held: it can log and change the outputs.
+func TestHookErrorLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+
+	logger.AddHook(HookFunc(level.Error, func(*Record) error {
+		return errors.New("webhook is unavailable")
+	}))
+
+	named := logger.Named("app")
+	named.SetHookErrorHandler(func(h Hook, r *Record, err error) {
+		named.Warnf("hook failed: %v", err)
+		logger.EditOutputs(Output{Name: "test", Levels: level.Warn})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		named.Error("failed")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the hook error handler is called under the lock")
+	}
+
+	if out := buf.String(); !strings.Contains(out, "failed") ||
+		!strings.Contains(out, "hook failed: webhook is unavailable") {
+		t.Errorf("incorrect output: %s", out)
+	}
+}
@@ -0,0 +1,111 @@
+package journald
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/syslog"
+)
+
+// The maxFieldName is the maximum length of the field name of journald.
+const maxFieldName = 64
+
+// Formatter is the log.Formatter that renders the records in the native
+// journal protocol: the MESSAGE, PRIORITY and SYSLOG_IDENTIFIER fields,
+// the CODE_FILE, CODE_LINE and CODE_FUNC fields of the caller, and the
+// fields of the record. The priorities are taken from syslog.Severities.
+//
+// The names of the record fields are converted to the names of journald:
+// the uppercase letters, digits and underscores, e.g. "user.id" is sent
+// as USER_ID. The caller is sent regardless of the Layouts of the output.
+type Formatter struct {
+	// Identifier is the SYSLOG_IDENTIFIER of the messages, by default,
+	// it's the prefix of the logger, or the name of the program.
+	Identifier string
+}
+
+// Format renders the record as the journal message.
+func (f Formatter) Format(r *log.Record, o *log.Output) ([]byte, error) {
+	priority, ok := syslog.Severities[r.Level]
+	if !ok {
+		priority = syslog.Debug
+	}
+
+	identifier := f.Identifier
+	switch {
+	case identifier != "":
+	case r.Prefix != "":
+		identifier = r.Prefix
+	default:
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	var buf []byte
+	// The trailing newline would force the binary encoding of the field.
+	buf = appendField(buf, "MESSAGE", strings.TrimRight(r.Message, "\n"))
+	buf = appendField(buf, "PRIORITY", strconv.Itoa(int(priority)))
+	buf = appendField(buf, "SYSLOG_IDENTIFIER", identifier)
+	if r.FilePath != "" {
+		buf = appendField(buf, "CODE_FILE", r.FilePath)
+		buf = appendField(buf, "CODE_LINE", strconv.Itoa(r.LineNumber))
+	}
+
+	if r.FuncName != "" {
+		buf = appendField(buf, "CODE_FUNC", r.FuncName)
+	}
+
+	for _, field := range r.Fields {
+		buf = appendField(buf, fieldName(field.Key), fmt.Sprint(field.Value))
+	}
+
+	return buf, nil
+}
+
+// The appendField appends the field to the message: NAME=value and
+// a newline, or, if the value has newlines, the name and a newline,
+// the length of the value as little-endian uint64, and the value.
+func appendField(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+	if !strings.Contains(value, "\n") {
+		buf = append(buf, '=')
+		buf = append(buf, value...)
+		return append(buf, '\n')
+	}
+
+	buf = append(buf, '\n')
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
+
+// The fieldName returns the name of the journald field: the uppercase
+// letters, digits and underscores, at most 64 of them, that doesn't
+// start with an underscore (reserved for the trusted fields) or a digit.
+func fieldName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, s)
+
+	s = strings.TrimLeft(s, "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "FIELD_" + s
+	}
+
+	if len(s) > maxFieldName {
+		s = s[:maxFieldName]
+	}
+
+	return s
+}
@@ -0,0 +1,120 @@
+package journald
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/level"
+)
+
+// TestFormatter tests the Format method of the Formatter.
+func TestFormatter(t *testing.T) {
+	record := func(l level.Level, p string, fields ...log.Field) *log.Record {
+		return &log.Record{
+			Prefix:     p,
+			Level:      l,
+			Message:    "request accepted",
+			Fields:     fields,
+			FilePath:   "/src/app/main.go",
+			LineNumber: 42,
+			FuncName:   "main",
+		}
+	}
+
+	caller := "CODE_FILE=/src/app/main.go\nCODE_LINE=42\nCODE_FUNC=main\n"
+	tests := []struct {
+		name      string
+		formatter Formatter
+		record    *log.Record
+		expected  string
+	}{
+		{
+			name:   "Prefix as identifier",
+			record: record(level.Info, "app"),
+			expected: "MESSAGE=request accepted\nPRIORITY=6\n" +
+				"SYSLOG_IDENTIFIER=app\n" + caller,
+		},
+		{
+			name:      "Identifier and fields",
+			formatter: Formatter{Identifier: "billing"},
+			record: record(level.Error, "app",
+				log.Field{Key: "user.id", Value: 7},
+				log.Field{Key: "_pid", Value: 1},
+			),
+			expected: "MESSAGE=request accepted\nPRIORITY=3\n" +
+				"SYSLOG_IDENTIFIER=billing\n" + caller +
+				"USER_ID=7\nPID=1\n",
+		},
+		{
+			name:   "Program name as identifier",
+			record: record(level.Panic, ""),
+			expected: "MESSAGE=request accepted\nPRIORITY=0\n" +
+				"SYSLOG_IDENTIFIER=" + filepath.Base(os.Args[0]) + "\n" +
+				caller,
+		},
+		{
+			name: "Multiline value",
+			record: record(level.Trace, "app",
+				log.Field{Key: "stack", Value: "a\nb"},
+			),
+			expected: "MESSAGE=request accepted\nPRIORITY=7\n" +
+				"SYSLOG_IDENTIFIER=app\n" + caller +
+				"STACK\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n",
+		},
+		{
+			name: "Trailing newline",
+			record: &log.Record{
+				Level:   level.Info,
+				Prefix:  "app",
+				Message: "cache miss k\n",
+			},
+			expected: "MESSAGE=cache miss k\nPRIORITY=6\n" +
+				"SYSLOG_IDENTIFIER=app\n",
+		},
+		{
+			name:   "Without caller",
+			record: &log.Record{Level: level.Warn, Prefix: "app"},
+			expected: "MESSAGE=\nPRIORITY=4\n" +
+				"SYSLOG_IDENTIFIER=app\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.formatter.Format(test.record, &log.Output{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(data); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestFieldName tests the fieldName function.
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"id", "ID"},
+		{"user.id", "USER_ID"},
+		{"Request-ID", "REQUEST_ID"},
+		{"__trusted", "TRUSTED"},
+		{"2fa", "FIELD_2FA"},
+		{"", "FIELD_"},
+		{"ключ", "FIELD_"},
+		{strings.Repeat("a", 70), strings.Repeat("A", 64)},
+	}
+
+	for _, test := range tests {
+		if got := fieldName(test.name); got != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package journald provides the systemd journal output for the logger:
+// the Formatter renders the records in the native journal protocol with
+// the PRIORITY, CODE_FILE, CODE_LINE, CODE_FUNC and SYSLOG_IDENTIFIER
+// fields, and the Writer sends them to the journal socket as datagrams.
+//
+// Example usage:
+//
+//	w := &journald.Writer{}
+//	defer w.Close()
+//
+//	logger.SetOutputs(log.Output{
+//	    Name:      "journal",
+//	    Writer:    w,
+//	    Formatter: journald.Formatter{},
+//	})
+package journald
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// The defaultPath is the path to the native protocol socket of journald.
+const defaultPath = "/run/systemd/journal/socket"
+
+// ErrClosed is returned by the Write of the closed Writer.
+var ErrClosed = errors.New("journald: writer is closed")
+
+// Writer is the io.WriteCloser that sends each written message to the
+// journal as one datagram, the message must be formatted by the Formatter.
+// The socket is connected on the first write and is reconnected when the
+// write fails. The message that is too large for a datagram is passed
+// to journald in a sealed memory file (memfd) on Linux. The Writer is
+// safe for concurrent use.
+type Writer struct {
+	// Path is the path to the journal socket,
+	// by default, /run/systemd/journal/socket.
+	Path string
+
+	conn   *net.UnixConn
+	closed bool
+
+	mu sync.Mutex
+}
+
+// Write sends p as one message. If the socket fails, the Writer
+// reconnects and sends the message again once.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return 0, err
+			}
+		}
+
+		if _, err = w.conn.Write(p); err == nil {
+			return len(p), nil
+		}
+
+		// The message is too large for the datagram,
+		// journald reads it from the memory file.
+		if tooLarge(err) {
+			if err = sendMemfd(w.conn, p); err != nil {
+				return 0, err
+			}
+
+			return len(p), nil
+		}
+
+		// The broken socket is reconnected immediately.
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	return 0, err
+}
+
+// Close closes the socket, further writes return ErrClosed.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// The connect connects to the journal socket.
+//
+// The method must be called with the writer's lock held.
+func (w *Writer) connect() error {
+	path := w.Path
+	if path == "" {
+		path = defaultPath
+	}
+
+	addr := &net.UnixAddr{Name: path, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	return nil
+}
@@ -0,0 +1,155 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/level"
+)
+
+// The listen binds the unixgram socket that stands in for journald.
+func listen(t *testing.T, path string) *net.UnixConn {
+	t.Helper()
+	addr := &net.UnixAddr{Name: path, Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+// The receive reads the datagram from the socket.
+func receive(t *testing.T, conn *net.UnixConn) []byte {
+	t.Helper()
+	buf := make([]byte, 64<<10)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf[:n]
+}
+
+// The parseFields decodes the message of the native journal protocol.
+func parseFields(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("incorrect field: %q", data)
+		}
+
+		name := string(data[:i])
+		if data[i] == '=' {
+			data = data[i+1:]
+			j := bytes.IndexByte(data, '\n')
+			fields[name], data = string(data[:j]), data[j+1:]
+			continue
+		}
+
+		data = data[i+1:]
+		n := binary.LittleEndian.Uint64(data)
+		fields[name] = string(data[8 : 8+n])
+		data = data[8+n+1:]
+	}
+
+	return fields
+}
+
+// TestWriter tests the Writer with the local socket.
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn := listen(t, path)
+
+	w := &Writer{Path: path}
+	msg := "MESSAGE=first\nPRIORITY=6\n"
+	if _, err := w.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(receive(t, conn)); got != msg {
+		t.Errorf("expected %q, got %q", msg, got)
+	}
+
+	// The socket of the restarted journald is reconnected.
+	conn.Close()
+	os.Remove(path)
+	conn = listen(t, path)
+	defer conn.Close()
+
+	msg = "MESSAGE=second\nPRIORITY=6\n"
+	if _, err := w.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(receive(t, conn)); got != msg {
+		t.Errorf("expected %q, got %q", msg, got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte(msg)); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// TestWriterNoSocket tests the Writer without journald.
+func TestWriterNoSocket(t *testing.T) {
+	w := &Writer{Path: filepath.Join(t.TempDir(), "missing.sock")}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("MESSAGE=lost\n")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// TestOutput tests the Formatter and the Writer as the logger output.
+func TestOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn := listen(t, path)
+	defer conn.Close()
+
+	w := &Writer{Path: path}
+	defer w.Close()
+
+	logger := log.New("billing")
+	logger.SetOutputs(log.Output{
+		Name:      "journal",
+		Writer:    w,
+		Formatter: Formatter{},
+		Levels:    level.Default,
+	})
+
+	logger.Warnw("payment declined\nretry later", "order.id", 7)
+	fields := parseFields(t, receive(t, conn))
+
+	expected := map[string]string{
+		"MESSAGE":           "payment declined\nretry later",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "billing",
+		"CODE_FUNC":         "TestOutput",
+		"ORDER_ID":          "7",
+	}
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("%s: expected %q, got %q", name, value, fields[name])
+		}
+	}
+
+	if filepath.Base(fields["CODE_FILE"]) != "journald_test.go" ||
+		fields["CODE_LINE"] == "" {
+		t.Errorf("incorrect caller: %v", fields)
+	}
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package journald
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// The flags of memfd_create and fcntl that are
+// not defined by the syscall package.
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+
+	fAddSeals = 0x409
+	fGetSeals = 0x40a
+
+	// The sealAll forbids to seal, shrink, grow
+	// and write the file, journald requires it.
+	sealAll = 0x1 | 0x2 | 0x4 | 0x8
+)
+
+// The memfdCreate is the number of the memfd_create
+// system call on the architectures of Linux.
+var memfdCreate = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// The tooLarge returns true if the error means that the
+// message doesn't fit into the datagram of the socket.
+func tooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// The sendMemfd writes p to the sealed memory file
+// and passes its descriptor to journald over the conn.
+func sendMemfd(conn *net.UnixConn, p []byte) error {
+	trap, ok := memfdCreate[runtime.GOARCH]
+	if !ok {
+		return fmt.Errorf("journald: memfd is not supported on %s",
+			runtime.GOARCH)
+	}
+
+	name, err := syscall.BytePtrFromString("journald")
+	if err != nil {
+		return err
+	}
+
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)),
+		mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return os.NewSyscallError("memfd_create", errno)
+	}
+
+	f := os.NewFile(fd, "journald")
+	defer f.Close()
+
+	if _, err := f.Write(p); err != nil {
+		return err
+	}
+
+	_, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, sealAll)
+	if errno != 0 {
+		return os.NewSyscallError("fcntl", errno)
+	}
+
+	// The socket is connected, so the descriptor is sent by sendmsg
+	// directly: WriteMsgUnix doesn't work with the connected datagrams.
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(fd))
+	werr := raw.Write(func(s uintptr) bool {
+		err = syscall.Sendmsg(int(s), nil, rights, nil, 0)
+		return err != syscall.EAGAIN
+	})
+	if werr != nil {
+		return werr
+	}
+
+	return os.NewSyscallError("sendmsg", err)
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package journald
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestWriterMemfd tests that the large message
+// is passed in the sealed memory file.
+func TestWriterMemfd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn := listen(t, path)
+	defer conn.Close()
+
+	w := &Writer{Path: path}
+	defer w.Close()
+
+	msg := append([]byte("MESSAGE="), bytes.Repeat([]byte("x"), 4<<20)...)
+	msg = append(msg, '\n')
+	if _, err := w.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Fatalf("expected the empty datagram, got %d bytes", n)
+	}
+
+	cmsgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(cmsgs) != 1 {
+		t.Fatalf("expected one control message, got %d: %v", len(cmsgs), err)
+	}
+
+	fds, err := syscall.ParseUnixRights(&cmsgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected one descriptor, got %d: %v", len(fds), err)
+	}
+
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	defer f.Close()
+
+	seals, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fGetSeals, 0)
+	if errno != 0 || seals&sealAll != sealAll {
+		t.Errorf("the file isn't sealed: %#x %v", seals, errno)
+	}
+
+	data := make([]byte, len(msg)+1)
+	n, _ = f.ReadAt(data, 0)
+	if !bytes.Equal(data[:n], msg) {
+		t.Errorf("expected %d bytes of the message, got %d", len(msg), n)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+)
+
+// The tooLarge returns false, the memory files
+// are supported on Linux only.
+func tooLarge(err error) bool {
+	return false
+}
+
+// The sendMemfd returns an error, the memory
+// files are supported on Linux only.
+func sendMemfd(conn *net.UnixConn, p []byte) error {
+	return errors.New("journald: memfd is supported on Linux only")
+}
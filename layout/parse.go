@@ -0,0 +1,154 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The canonicalNames associates the canonical names with layouts,
+// the names are listed in order of the flags.
+var canonicalNames = []struct {
+	name   string
+	layout Layout
+}{
+	{"full-path", FullFilePath},
+	{"short-path", ShortFilePath},
+	{"func", FuncName},
+	{"func-address", FuncAddress},
+	{"line", LineNumber},
+}
+
+// The names associates the names and aliases used by Parse with layouts.
+var names = map[string]Layout{
+	"full-path":       FullFilePath,
+	"full-file-path":  FullFilePath,
+	"short-path":      ShortFilePath,
+	"short-file-path": ShortFilePath,
+	"func":            FuncName,
+	"func-name":       FuncName,
+	"func-address":    FuncAddress,
+	"address":         FuncAddress,
+	"line":            LineNumber,
+	"line-number":     LineNumber,
+}
+
+// Parse parses the layouts from the string of names separated by
+// commas or vertical bars, the names are case-insensitive:
+//   - "full-path" (alias "full-file-path") for FullFilePath;
+//   - "short-path" (alias "short-file-path") for ShortFilePath;
+//   - "func" (alias "func-name") for FuncName;
+//   - "func-address" (alias "address") for FuncAddress;
+//   - "line" (alias "line-number") for LineNumber;
+//   - "default" for Default, "none" for no layouts.
+//
+// Example usage:
+//
+//	layout.Parse("short-path,func,line") // Default
+func Parse(s string) (Layout, error) {
+	var result Layout
+	terms := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+
+	if len(terms) == 0 {
+		return 0, fmt.Errorf("incorrect layout '%s'", s)
+	}
+
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		switch term {
+		case "default":
+			result |= Default
+			continue
+		case "none":
+			continue
+		}
+
+		l, ok := names[term]
+		if !ok {
+			return 0, fmt.Errorf("unknown layout '%s'", term)
+		}
+
+		result |= l
+	}
+
+	return result, nil
+}
+
+// String returns the canonical string form of the layouts: the names
+// separated by commas, e.g. "short-path,func,line", or "none" for the
+// zero value. The string can be parsed by Parse.
+func (l Layout) String() string {
+	if l == 0 {
+		return "none"
+	}
+
+	result := make([]string, 0, len(canonicalNames))
+	for _, v := range canonicalNames {
+		if l&v.layout != 0 {
+			result = append(result, v.name)
+		}
+	}
+
+	if !l.IsValid() {
+		unknown := l &^ (overflowLayoutValue - 1)
+		result = append(result, fmt.Sprintf("%#x", uint8(unknown)))
+	}
+
+	return strings.Join(result, ",")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns an error if the value is invalid.
+func (l Layout) MarshalText() ([]byte, error) {
+	if !l.IsValid() {
+		return nil, fmt.Errorf("the %d is an invalid layout value", uint8(l))
+	}
+
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler
+// interface, the text is parsed by Parse.
+func (l *Layout) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface,
+// the value is marshaled as the string, see String.
+func (l Layout) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value
+// can be the string parsed by Parse or the number of the bitmask.
+func (l *Layout) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return l.UnmarshalText([]byte(s))
+	}
+
+	var n uint8
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("incorrect layout %s", data)
+	}
+
+	if v := Layout(n); !v.IsValid() {
+		return fmt.Errorf("the %d is an invalid layout value", n)
+	}
+
+	*l = Layout(n)
+	return nil
+}
@@ -0,0 +1,106 @@
+package layout
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestParse tests the Parse function.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect Layout
+		err    bool
+	}{
+		{
+			name:   "Default",
+			value:  "short-path,func,line",
+			expect: Default,
+		},
+		{
+			name:   "Aliases",
+			value:  "Full-File-Path|address",
+			expect: FullFilePath | FuncAddress,
+		},
+		{
+			name:   "Named default",
+			value:  "default,address",
+			expect: Default | FuncAddress,
+		},
+		{name: "None", value: "none", expect: 0},
+		{name: "Empty", value: "", err: true},
+		{name: "Unknown", value: "func,column", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.value)
+			if (err != nil) != test.err {
+				t.Fatalf("Parse(%q) error = %v", test.value, err)
+			}
+
+			if got != test.expect {
+				t.Errorf("Parse(%q) = %05b; want %05b",
+					test.value, got, test.expect)
+			}
+		})
+	}
+}
+
+// TestString tests the round trip of the String and Parse.
+func TestString(t *testing.T) {
+	if s := Default.String(); s != "short-path,func,line" {
+		t.Errorf("String = %s; want short-path,func,line", s)
+	}
+
+	for l := Layout(0); l < overflowLayoutValue; l++ {
+		got, err := Parse(l.String())
+		if err != nil || got != l {
+			t.Errorf("Parse(%q) = %05b, %v; want %05b", l, got, err, l)
+		}
+
+		// The vertical bars are accepted as the separators too.
+		s := strings.ReplaceAll(l.String(), ",", "|")
+		if got, err := Parse(s); err != nil || got != l {
+			t.Errorf("Parse(%q) = %05b, %v; want %05b", s, got, err, l)
+		}
+	}
+}
+
+// TestJSON tests the JSON and text marshaling.
+func TestJSON(t *testing.T) {
+	v := struct {
+		Layouts Layout `json:"layouts"`
+	}{Layouts: FullFilePath | LineNumber}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"layouts":"full-path,line"}` {
+		t.Errorf("incorrect JSON: %s", data)
+	}
+
+	v.Layouts = 0
+	if err := json.Unmarshal(data, &v); err != nil ||
+		v.Layouts != FullFilePath|LineNumber {
+		t.Errorf("Unmarshal = %05b, %v", v.Layouts, err)
+	}
+
+	// The bitmask number.
+	if err := json.Unmarshal([]byte(`{"layouts":5}`), &v); err != nil ||
+		v.Layouts != FullFilePath|FuncName {
+		t.Errorf("Unmarshal = %05b, %v", v.Layouts, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"layouts":64}`), &v); err == nil {
+		t.Error("expected an error for the invalid value")
+	}
+
+	if _, err := (overflowLayoutValue).MarshalText(); err == nil {
+		t.Error("expected an error for the invalid value")
+	}
+}
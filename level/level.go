@@ -212,3 +212,36 @@ func (l *Level) Any(flags ...Level) bool {
 
 	return false
 }
+
+// AtLeast returns the levels that are at least as severe as the
+// specified one: AtLeast(Warn) is Panic|Fatal|Error|Warn. If l
+// contains several flags, the least severe of them is used.
+func AtLeast(l Level) Level {
+	l &= Default
+	if l == 0 {
+		return 0
+	}
+
+	top := Level(1) << (bits.Len8(uint8(l)) - 1)
+	return (top<<1 - 1) & Default
+}
+
+// AtMost returns the levels that are at most as severe as the
+// specified one: AtMost(Debug) is Debug|Trace. If l contains
+// several flags, the most severe of them is used.
+func AtMost(l Level) Level {
+	l &= Default
+	if l == 0 {
+		return 0
+	}
+
+	low := l & -l
+	return Default &^ (low - 1)
+}
+
+// Between returns the levels between the specified ones, inclusive:
+// Between(Warn, Debug) is Warn|Info|Debug. The order of the
+// arguments doesn't matter.
+func Between(a, b Level) Level {
+	return AtLeast(a|b) & AtMost(a|b)
+}
@@ -343,3 +343,32 @@ func TestLevelAny(t *testing.T) {
 		})
 	}
 }
+
+// TestThresholds tests the AtLeast, AtMost and Between functions.
+func TestThresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		got    Level
+		expect Level
+	}{
+		{"AtLeast Warn", AtLeast(Warn), Panic | Fatal | Error | Warn},
+		{"AtLeast Panic", AtLeast(Panic), Panic},
+		{"AtLeast Trace", AtLeast(Trace), Default},
+		{"AtLeast mask", AtLeast(Error | Info), AtLeast(Info)},
+		{"AtLeast zero", AtLeast(0), 0},
+		{"AtMost Debug", AtMost(Debug), Debug | Trace},
+		{"AtMost Panic", AtMost(Panic), Default},
+		{"AtMost mask", AtMost(Error | Info), AtMost(Error)},
+		{"Between", Between(Warn, Debug), Warn | Info | Debug},
+		{"Between reversed", Between(Debug, Warn), Warn | Info | Debug},
+		{"Between same", Between(Error, Error), Error},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.got != test.expect {
+				t.Errorf("got %07b; want %07b", test.got, test.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package level
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The canonicalNames associates the canonical names with log levels,
+// the names are listed in order of the flags, from the most severe.
+var canonicalNames = []struct {
+	name  string
+	level Level
+}{
+	{"panic", Panic},
+	{"fatal", Fatal},
+	{"error", Error},
+	{"warn", Warn},
+	{"info", Info},
+	{"debug", Debug},
+	{"trace", Trace},
+}
+
+// The names associates the names and aliases used by Parse
+// with log levels.
+var names = map[string]Level{
+	"panic":   Panic,
+	"fatal":   Fatal,
+	"error":   Error,
+	"err":     Error,
+	"warn":    Warn,
+	"warning": Warn,
+	"info":    Info,
+	"debug":   Debug,
+	"trace":   Trace,
+}
+
+// Parse parses the levels from the string of terms separated by commas
+// or vertical bars, the names are case-insensitive. The term can be:
+//   - the name of the level: "error", "warn" etc., or its alias:
+//     "err" for "error", "warning" for "warn";
+//   - the threshold: ">=warn", ">warn", "<=debug", "<debug";
+//   - "all" for all the levels, "none" for no levels;
+//   - any of the above with the "-" prefix to remove the levels.
+//
+// The terms are applied from left to right.
+//
+// Example usage:
+//
+//	level.Parse(">=warn")      // Panic|Fatal|Error|Warn
+//	level.Parse("error,fatal") // Error|Fatal
+//	level.Parse("error|warn")  // Error|Warn
+//	level.Parse("all,-trace")  // Default without Trace
+func Parse(s string) (Level, error) {
+	var result Level
+	if strings.TrimSpace(s) == "" {
+		return 0, fmt.Errorf("empty level")
+	}
+
+	terms := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+
+	if len(terms) == 0 {
+		return 0, fmt.Errorf("incorrect level '%s'", s)
+	}
+
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		remove := strings.HasPrefix(term, "-")
+		if remove {
+			term = strings.TrimSpace(term[1:])
+		}
+
+		l, err := parseTerm(term)
+		if err != nil {
+			return 0, err
+		}
+
+		if remove {
+			result &^= l
+		} else {
+			result |= l
+		}
+	}
+
+	return result, nil
+}
+
+// The parseTerm parses a single term of the Parse string.
+func parseTerm(term string) (Level, error) {
+	switch term {
+	case "all":
+		return Default, nil
+	case "none":
+		return 0, nil
+	}
+
+	op := ""
+	for _, v := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(term, v) {
+			op, term = v, strings.TrimSpace(term[len(v):])
+			break
+		}
+	}
+
+	l, ok := names[term]
+	if !ok {
+		return 0, fmt.Errorf("unknown level '%s'", term)
+	}
+
+	switch op {
+	case ">=":
+		return AtLeast(l), nil
+	case ">":
+		return AtLeast(l) &^ l, nil
+	case "<=":
+		return AtMost(l), nil
+	case "<":
+		return AtMost(l) &^ l, nil
+	}
+
+	return l, nil
+}
+
+// String returns the canonical string form of the levels: the names
+// separated by commas in order of severity, e.g. "error,warn", or
+// "none" for the zero value, like layout.Layout.String. The string
+// can be parsed by Parse.
+func (l Level) String() string {
+	if l == 0 {
+		return "none"
+	}
+
+	result := make([]string, 0, len(canonicalNames))
+	for _, v := range canonicalNames {
+		if l&v.level != 0 {
+			result = append(result, v.name)
+		}
+	}
+
+	if !l.IsValid() {
+		result = append(result, fmt.Sprintf("%#x", uint8(l&^Default)))
+	}
+
+	return strings.Join(result, ",")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns an error if the value is invalid.
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.IsValid() {
+		return nil, fmt.Errorf("the %d is an invalid level value", uint8(l))
+	}
+
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler
+// interface, the text is parsed by Parse.
+func (l *Level) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface,
+// the value is marshaled as the string, see String.
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value
+// can be the string parsed by Parse or the number of the bitmask.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return l.UnmarshalText([]byte(s))
+	}
+
+	var n uint8
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("incorrect level %s", data)
+	}
+
+	if v := Level(n); !v.IsValid() {
+		return fmt.Errorf("the %d is an invalid level value", n)
+	}
+
+	*l = Level(n)
+	return nil
+}
@@ -0,0 +1,126 @@
+package level
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestParse tests the Parse function.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect Level
+		err    bool
+	}{
+		{name: "Name", value: "info", expect: Info},
+		{name: "List", value: "error, FATAL", expect: Error | Fatal},
+		{name: "At least", value: ">=warn", expect: AtLeast(Warn)},
+		{name: "More severe", value: ">warn", expect: Panic | Fatal | Error},
+		{name: "At most", value: "<= debug", expect: Debug | Trace},
+		{name: "Less severe", value: "<debug", expect: Trace},
+		{name: "All", value: "all", expect: Default},
+		{name: "All without", value: "all,-trace", expect: Default &^ Trace},
+		{name: "Remove range", value: "all,->=error", expect: AtMost(Warn)},
+		{name: "Empty", value: " ", err: true},
+		{name: "Unknown", value: "info,verbose", err: true},
+		{name: "Operator only", value: ">=", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.value)
+			if (err != nil) != test.err {
+				t.Fatalf("Parse(%q) error = %v", test.value, err)
+			}
+
+			if got != test.expect {
+				t.Errorf("Parse(%q) = %07b; want %07b",
+					test.value, got, test.expect)
+			}
+		})
+	}
+}
+
+// TestParseAliases tests the separators and aliases of the Parse.
+func TestParseAliases(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect Level
+	}{
+		{"error|warn", Error | Warn},
+		{"ERR|Warning", Error | Warn},
+		{"none", 0},
+		{"all|-err", Default &^ Error},
+	}
+
+	for _, test := range tests {
+		if got, err := Parse(test.value); err != nil || got != test.expect {
+			t.Errorf("Parse(%q) = %07b, %v; want %07b",
+				test.value, got, err, test.expect)
+		}
+	}
+}
+
+// TestString tests the round trip of the String and Parse.
+func TestString(t *testing.T) {
+	if s := (Warn | Error).String(); s != "error,warn" {
+		t.Errorf("String = %s; want error,warn", s)
+	}
+
+	for l := Level(0); l < overflowLevelValue; l++ {
+		got, err := Parse(l.String())
+		if err != nil || got != l {
+			t.Errorf("Parse(%q) = %07b, %v; want %07b", l, got, err, l)
+		}
+
+		// The vertical bars are accepted as the separators too.
+		s := strings.ReplaceAll(l.String(), ",", "|")
+		if got, err := Parse(s); err != nil || got != l {
+			t.Errorf("Parse(%q) = %07b, %v; want %07b", s, got, err, l)
+		}
+	}
+}
+
+// TestJSON tests the JSON and text marshaling.
+func TestJSON(t *testing.T) {
+	v := struct {
+		Levels Level `json:"levels"`
+	}{Levels: AtLeast(Error)}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"levels":"panic,fatal,error"}` {
+		t.Errorf("incorrect JSON: %s", data)
+	}
+
+	v.Levels = 0
+	if err := json.Unmarshal(data, &v); err != nil ||
+		v.Levels != AtLeast(Error) {
+		t.Errorf("Unmarshal = %07b, %v", v.Levels, err)
+	}
+
+	// The threshold and the bitmask number.
+	if err := json.Unmarshal([]byte(`{"levels":">=warn"}`), &v); err != nil ||
+		v.Levels != AtLeast(Warn) {
+		t.Errorf("Unmarshal = %07b, %v", v.Levels, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"levels":3}`), &v); err != nil ||
+		v.Levels != Panic|Fatal {
+		t.Errorf("Unmarshal = %07b, %v", v.Levels, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"levels":"verbose"}`), &v); err == nil {
+		t.Error("expected an error for the unknown level")
+	}
+
+	var text Level
+	if err := text.UnmarshalText([]byte("warning")); err != nil || text != Warn {
+		t.Errorf("UnmarshalText = %07b, %v", text, err)
+	}
+}
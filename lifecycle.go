@@ -0,0 +1,204 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"syscall"
+)
+
+// Flush writes all the messages that were logged before the call:
+// it waits until the asynchronous outputs write their queues, and
+// flushes the writers that buffer data. See FlushContext.
+func (logger *Logger) Flush() error {
+	return logger.FlushContext(context.Background())
+}
+
+// FlushContext writes the pending reports of the repeated records (see
+// Output.Dedup), waits until the asynchronous outputs write all the
+// messages that were logged before the call, or the ctx is done, then
+// flushes the writers of the outputs: calls Flush() for writers such as
+// bufio.Writer or gzip.Writer, and Sync() for writers such as os.File.
+//
+// It returns the ctx error if the wait was interrupted, or the errors
+// of the writers joined by errors.Join.
+func (logger *Logger) FlushContext(ctx context.Context) error {
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.FlushContext(ctx)
+	}
+
+	logger.flushAllRepeats()
+	for _, q := range logger.queues() {
+		if err := q.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The exclusive lock: the writers don't receive
+	// messages while they are being flushed.
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	var errs []error
+	for _, w := range logger.writers() {
+		errs = append(errs, flushWriter(w))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes the logger: it writes the messages queued by
+// the asynchronous outputs and stops their goroutines, flushes the
+// writers (see Flush) and closes the writers that implement io.Closer,
+// except os.Stdout and os.Stderr. The errors are joined by errors.Join.
+//
+// Further logging is a no-op: the messages are not written, and their
+// number is returned by DroppedAfterClose. A new list of outputs can be
+// installed by SetOutputs, it opens the logger again.
+//
+// Note: the writers are closed even if they are used by copies of the
+// logger (see Copy and With).
+func (logger *Logger) Close() error {
+	return logger.closeContext(context.Background())
+}
+
+// DroppedAfterClose returns the number of messages that were
+// not written because they were logged after Close.
+func (logger *Logger) DroppedAfterClose() uint64 {
+	return logger.base().afterClose.Load()
+}
+
+// The closeContext closes the logger, see Close. The ctx limits
+// the wait for the queues of the asynchronous outputs.
+func (logger *Logger) closeContext(ctx context.Context) error {
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.closeContext(ctx)
+	}
+
+	logger.flushAllRepeats()
+	logger.mu.Lock()
+	if logger.closed {
+		logger.mu.Unlock()
+		return nil
+	}
+
+	logger.closed = true
+	logger.mu.Unlock()
+
+	var errs []error
+	for _, q := range logger.queues() {
+		errs = append(errs, q.close(ctx))
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	for _, w := range logger.writers() {
+		errs = append(errs, flushWriter(w))
+		if c, ok := w.(io.Closer); ok && !isStdStream(w) {
+			errs = append(errs, c.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// The queues returns the list of the queues of the asynchronous outputs.
+func (logger *Logger) queues() []*asyncQueue {
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	result := make([]*asyncQueue, 0, len(logger.outputs))
+	for _, o := range logger.outputs {
+		if o.queue != nil {
+			result = append(result, o.queue)
+		}
+	}
+
+	return result
+}
+
+// The writers returns the list of unique writers of the outputs.
+// The writers of the system outputs (passed to the F* methods)
+// belong to the caller and are not included.
+//
+// The method must be called with the logger's lock held.
+func (logger *Logger) writers() []io.Writer {
+	seen := make(map[io.Writer]bool, len(logger.outputs))
+	result := make([]io.Writer, 0, len(logger.outputs))
+	for _, o := range logger.outputs {
+		if o.isSystem || o.Writer == nil {
+			continue
+		}
+
+		// Writers of not comparable types cannot be map keys.
+		if reflect.TypeOf(o.Writer).Comparable() {
+			if seen[o.Writer] {
+				continue
+			}
+			seen[o.Writer] = true
+		}
+
+		result = append(result, o.Writer)
+	}
+
+	return result
+}
+
+// The flushWithTimeout flushes the logger before the panic or the
+// program termination, the wait is limited by the fatalFlushTimeout.
+//
+// Note: the logger is not closed, since the panic can be recovered
+// and the exit function can be intercepted, so the program can keep
+// working with the logger.
+func (logger *Logger) flushWithTimeout() {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		fatalFlushTimeout,
+	)
+	defer cancel()
+
+	logger.FlushContext(ctx)
+}
+
+// The terminate flushes the logger and terminates
+// the program with the fatal status code.
+func (logger *Logger) terminate() {
+	logger.flushWithTimeout()
+	exit(logger.fatalStatusCode)
+}
+
+// The flushWriter flushes the writer if it buffers data: calls Flush()
+// or Sync(). The error of Sync() for files that don't support it (e.g.
+// pipes and terminals) is ignored.
+func flushWriter(w io.Writer) error {
+	switch v := w.(type) {
+	case interface{ Flush() error }:
+		return v.Flush()
+	case interface{ Flush() }:
+		v.Flush()
+	case interface{ Sync() error }:
+		if isStdStream(w) {
+			return nil
+		}
+
+		err := v.Sync()
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, os.ErrInvalid) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// The isStdStream returns true if the writer is os.Stdout or os.Stderr.
+func isStdStream(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (f == os.Stdout || f == os.Stderr)
+}
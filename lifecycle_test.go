@@ -0,0 +1,159 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+)
+
+// The closeWriter is the writer that records the calls
+// of the Flush and Close methods.
+type closeWriter struct {
+	buf     bytes.Buffer
+	flushed int
+	closed  int
+	err     error
+}
+
+// Write writes p to the buffer.
+func (w *closeWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Flush counts the calls.
+func (w *closeWriter) Flush() error {
+	w.flushed++
+	return nil
+}
+
+// Close counts the calls and returns the preset error.
+func (w *closeWriter) Close() error {
+	w.closed++
+	return w.err
+}
+
+// TestFlush tests the Flush method for the buffered writers.
+func TestFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bw := bufio.NewWriter(buf)
+
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "buffered",
+		Writer: bw,
+		Levels: level.Default,
+	})
+
+	logger.Infoln("buffered message")
+	if buf.Len() != 0 {
+		t.Fatalf("the message is not expected before Flush: %s", buf)
+	}
+
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "buffered message") {
+		t.Errorf("the message was not flushed: %s", buf)
+	}
+}
+
+// TestClose tests the Close method.
+func TestClose(t *testing.T) {
+	first := &closeWriter{err: errors.New("first")}
+	second := &closeWriter{err: errors.New("second")}
+
+	logger := New()
+	logger.SetOutputs(
+		Output{Name: "first", Writer: first, Levels: level.Default},
+		Output{Name: "second", Writer: second, Levels: level.Default},
+		Output{Name: "copy", Writer: second, Levels: level.Default},
+		Output{Name: "stdout", Writer: os.Stdout, Levels: level.Panic},
+	)
+
+	logger.Infoln("before close")
+	err := logger.Close()
+	if !errors.Is(err, first.err) || !errors.Is(err, second.err) {
+		t.Errorf("expected the joined errors, got %v", err)
+	}
+
+	// The shared writer is closed once.
+	if first.closed != 1 || second.closed != 1 || second.flushed != 1 {
+		t.Errorf("incorrect calls: first %d, second %d/%d",
+			first.closed, second.closed, second.flushed)
+	}
+
+	// Logging after Close is a no-op.
+	logger.Infoln("after close")
+	logger.Errorf("after close %d", 2)
+	if strings.Contains(first.buf.String(), "after close") {
+		t.Errorf("the message was written after Close: %s", &first.buf)
+	}
+
+	if got := logger.DroppedAfterClose(); got != 2 {
+		t.Errorf("expected 2 dropped messages, got %d", got)
+	}
+
+	// The repeated call does nothing.
+	if err := logger.Close(); err != nil || first.closed != 1 {
+		t.Errorf("the repeated Close: %v, %d", err, first.closed)
+	}
+
+	// The new outputs open the logger.
+	buf := &bytes.Buffer{}
+	logger.SetOutputs(Output{Name: "new", Writer: buf, Levels: level.Default})
+	logger.Infoln("reopened")
+	if !strings.Contains(buf.String(), "reopened") {
+		t.Errorf("the logger was not reopened: %s", buf)
+	}
+}
+
+// TestSystemOutput tests that the writer passed to the F* methods
+// is used for the current message only and is not closed.
+func TestSystemOutput(t *testing.T) {
+	w := &closeWriter{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "main",
+		Writer: &bytes.Buffer{},
+		Levels: level.Default,
+	})
+
+	logger.Finfoln(w, "system")
+	if len(logger.Outputs()) != 1 {
+		t.Errorf("the system output was saved: %v", logger.Outputs())
+	}
+
+	logger.Close()
+	if w.closed != 0 || w.flushed != 0 {
+		t.Errorf("the system writer was closed")
+	}
+}
+
+// TestPanicFlush tests that Panic flushes the buffered writers.
+func TestPanicFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "buffered",
+		Writer: bufio.NewWriter(buf),
+		Levels: level.Default,
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+
+		if !strings.Contains(buf.String(), "panic message") {
+			t.Errorf("the message was not flushed: %s", buf)
+		}
+	}()
+
+	logger.Panicln("panic message")
+}
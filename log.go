@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"io"
 	"strings"
 	"sync"
@@ -130,6 +131,16 @@ func Copy() *Logger {
 	return self.Copy()
 }
 
+// With returns a copy of the default logger that carries the specified
+// key/value pairs as fields of each log-message.
+func With(kv ...any) *Logger {
+	// The default logger works at the imported package level,
+	// the child logger is used directly.
+	logger := self.With(kv...)
+	logger.SetSkipStackFrames(logger.SkipStackFrames() - 1)
+	return logger
+}
+
 // SetSkipStackFrames sets skip stack frames level.
 func SetSkipStackFrames(skips int) {
 	self.SetSkipStackFrames(skips)
@@ -170,6 +181,31 @@ func Outputs(names ...string) []Output {
 	return self.Outputs(names...)
 }
 
+// Flush writes all the messages of the default logger that were logged
+// before the call and flushes the writers that buffer data.
+func Flush() error {
+	return self.Flush()
+}
+
+// FlushContext waits until the asynchronous outputs of the default logger
+// write all the messages that were logged before the call, or the ctx
+// is done, then flushes the writers that buffer data.
+func FlushContext(ctx context.Context) error {
+	return self.FlushContext(ctx)
+}
+
+// Close flushes and closes the writers of the default logger,
+// further logging is a no-op. See Logger.Close.
+func Close() error {
+	return self.Close()
+}
+
+// DroppedAfterClose returns the number of messages of the default
+// logger that were not written because they were logged after Close.
+func DroppedAfterClose() uint64 {
+	return self.DroppedAfterClose()
+}
+
 // Fpanic creates message with Panic level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -210,6 +246,13 @@ func Panicln(a ...any) {
 	self.Panicln(a...)
 }
 
+// Panicw creates message with Panic level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Panicw(msg string, kv ...any) {
+	self.Panicw(msg, kv...)
+}
+
 // Ffatal creates message with Fatal level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -253,6 +296,13 @@ func Fatalln(a ...any) {
 	self.Fatalln(a...)
 }
 
+// Fatalw creates message with Fatal level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Fatalw(msg string, kv ...any) {
+	self.Fatalw(msg, kv...)
+}
+
 // Ferror creates message with Error level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -296,6 +346,13 @@ func Errorln(a ...any) {
 	self.Errorln(a...)
 }
 
+// Errorw creates message with Error level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Errorw(msg string, kv ...any) {
+	self.Errorw(msg, kv...)
+}
+
 // Fwarn creates message with Warn level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -339,6 +396,13 @@ func Warnln(a ...any) {
 	self.Warnln(a...)
 }
 
+// Warnw creates message with Warn level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Warnw(msg string, kv ...any) {
+	self.Warnw(msg, kv...)
+}
+
 // Finfo creates message with Info level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -383,6 +447,13 @@ func Infoln(a ...any) {
 	self.Infoln(a...)
 }
 
+// Infow creates message with Info level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Infow(msg string, kv ...any) {
+	self.Infow(msg, kv...)
+}
+
 // Fdebug creates message with Debug level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -426,6 +497,13 @@ func Debugln(a ...any) {
 	self.Debugln(a...)
 }
 
+// Debugw creates message with Debug level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Debugw(msg string, kv ...any) {
+	self.Debugw(msg, kv...)
+}
+
 // Ftrace creates message with Trace level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
@@ -468,3 +546,318 @@ func Tracef(format string, a ...any) {
 func Traceln(a ...any) {
 	self.Traceln(a...)
 }
+
+// Tracew creates message with Trace level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields. A newline is appended.
+func Tracew(msg string, kv ...any) {
+	self.Tracew(msg, kv...)
+}
+
+// FpanicContext creates message with Panic level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FpanicContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FpanicContext(ctx, w, a...)
+}
+
+// FpanicfContext creates message with Panic level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FpanicfContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FpanicfContext(ctx, w, format, a...)
+}
+
+// FpaniclnContext creates message with Panic level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FpaniclnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FpaniclnContext(ctx, w, a...)
+}
+
+// PanicContext creates message with Panic level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func PanicContext(ctx context.Context, a ...any) {
+	self.PanicContext(ctx, a...)
+}
+
+// PanicfContext creates message with Panic level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func PanicfContext(ctx context.Context, format string, a ...any) {
+	self.PanicfContext(ctx, format, a...)
+}
+
+// PaniclnContext creates message with Panic level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func PaniclnContext(ctx context.Context, a ...any) {
+	self.PaniclnContext(ctx, a...)
+}
+
+// FfatalContext creates message with Fatal level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FfatalContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FfatalContext(ctx, w, a...)
+}
+
+// FfatalfContext creates message with Fatal level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FfatalfContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FfatalfContext(ctx, w, format, a...)
+}
+
+// FfatallnContext creates message with Fatal level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FfatallnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FfatallnContext(ctx, w, a...)
+}
+
+// FatalContext creates message with Fatal level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func FatalContext(ctx context.Context, a ...any) {
+	self.FatalContext(ctx, a...)
+}
+
+// FatalfContext creates message with Fatal level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func FatalfContext(ctx context.Context, format string, a ...any) {
+	self.FatalfContext(ctx, format, a...)
+}
+
+// FatallnContext creates message with Fatal level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func FatallnContext(ctx context.Context, a ...any) {
+	self.FatallnContext(ctx, a...)
+}
+
+// FerrorContext creates message with Error level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FerrorContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FerrorContext(ctx, w, a...)
+}
+
+// FerrorfContext creates message with Error level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FerrorfContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FerrorfContext(ctx, w, format, a...)
+}
+
+// FerrorlnContext creates message with Error level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FerrorlnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FerrorlnContext(ctx, w, a...)
+}
+
+// ErrorContext creates message with Error level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func ErrorContext(ctx context.Context, a ...any) {
+	self.ErrorContext(ctx, a...)
+}
+
+// ErrorfContext creates message with Error level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func ErrorfContext(ctx context.Context, format string, a ...any) {
+	self.ErrorfContext(ctx, format, a...)
+}
+
+// ErrorlnContext creates message with Error level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func ErrorlnContext(ctx context.Context, a ...any) {
+	self.ErrorlnContext(ctx, a...)
+}
+
+// FwarnContext creates message with Warn level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FwarnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FwarnContext(ctx, w, a...)
+}
+
+// FwarnfContext creates message with Warn level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FwarnfContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FwarnfContext(ctx, w, format, a...)
+}
+
+// FwarnlnContext creates message with Warn level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FwarnlnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FwarnlnContext(ctx, w, a...)
+}
+
+// WarnContext creates message with Warn level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func WarnContext(ctx context.Context, a ...any) {
+	self.WarnContext(ctx, a...)
+}
+
+// WarnfContext creates message with Warn level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func WarnfContext(ctx context.Context, format string, a ...any) {
+	self.WarnfContext(ctx, format, a...)
+}
+
+// WarnlnContext creates message with Warn level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func WarnlnContext(ctx context.Context, a ...any) {
+	self.WarnlnContext(ctx, a...)
+}
+
+// FinfoContext creates message with Info level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FinfoContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FinfoContext(ctx, w, a...)
+}
+
+// FinfofContext creates message with Info level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FinfofContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FinfofContext(ctx, w, format, a...)
+}
+
+// FinfolnContext creates message with Info level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FinfolnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FinfolnContext(ctx, w, a...)
+}
+
+// InfoContext creates message with Info level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func InfoContext(ctx context.Context, a ...any) {
+	self.InfoContext(ctx, a...)
+}
+
+// InfofContext creates message with Info level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func InfofContext(ctx context.Context, format string, a ...any) {
+	self.InfofContext(ctx, format, a...)
+}
+
+// InfolnContext creates message with Info level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func InfolnContext(ctx context.Context, a ...any) {
+	self.InfolnContext(ctx, a...)
+}
+
+// FdebugContext creates message with Debug level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FdebugContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FdebugContext(ctx, w, a...)
+}
+
+// FdebugfContext creates message with Debug level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FdebugfContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FdebugfContext(ctx, w, format, a...)
+}
+
+// FdebuglnContext creates message with Debug level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FdebuglnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FdebuglnContext(ctx, w, a...)
+}
+
+// DebugContext creates message with Debug level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func DebugContext(ctx context.Context, a ...any) {
+	self.DebugContext(ctx, a...)
+}
+
+// DebugfContext creates message with Debug level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func DebugfContext(ctx context.Context, format string, a ...any) {
+	self.DebugfContext(ctx, format, a...)
+}
+
+// DebuglnContext creates message with Debug level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func DebuglnContext(ctx context.Context, a ...any) {
+	self.DebuglnContext(ctx, a...)
+}
+
+// FtraceContext creates message with Trace level, using the default formats
+// for its operands and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FtraceContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FtraceContext(ctx, w, a...)
+}
+
+// FtracefContext creates message with Trace level, according to a format
+// specifier and writes to w. The fields extracted from the ctx
+// are added to the message.
+func FtracefContext(ctx context.Context, w io.Writer, format string, a ...any) {
+	self.FtracefContext(ctx, w, format, a...)
+}
+
+// FtracelnContext creates message with Trace level, using the default formats
+// for its operands and writes to w. Spaces are always added between
+// operands and a newline is appended. The fields extracted from the ctx
+// are added to the message.
+func FtracelnContext(ctx context.Context, w io.Writer, a ...any) {
+	self.FtracelnContext(ctx, w, a...)
+}
+
+// TraceContext creates message with Trace level, using the default formats
+// for its operands and writes to log.Writer. The fields extracted from
+// the ctx are added to the message.
+func TraceContext(ctx context.Context, a ...any) {
+	self.TraceContext(ctx, a...)
+}
+
+// TracefContext creates message with Trace level, according to a format
+// specifier and writes to log.Writer. The fields extracted from the ctx
+// are added to the message.
+func TracefContext(ctx context.Context, format string, a ...any) {
+	self.TracefContext(ctx, format, a...)
+}
+
+// TracelnContext creates message with Trace level, using the default formats
+// for its operands and writes to log.Writer. Spaces are always added
+// between operands and a newline is appended. The fields extracted from
+// the ctx are added to the message.
+func TracelnContext(ctx context.Context, a ...any) {
+	self.TracelnContext(ctx, a...)
+}
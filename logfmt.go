@@ -0,0 +1,134 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goloop/log/level"
+)
+
+// The logfmtReservedKeys are the keys of the header of the logfmt
+// message. A field with one of these keys is moved to the "fields."
+// namespace so that it cannot be confused with the header.
+var logfmtReservedKeys = map[string]bool{
+	"ts":     true,
+	"level":  true,
+	"prefix": true,
+	"caller": true,
+	"line":   true,
+	"func":   true,
+	"addr":   true,
+	"msg":    true,
+}
+
+// LogfmtFormatter is the built-in formatter of the logfmt style: the
+// record is rendered as a line of key=value pairs, e.g.
+//
+//	ts="2023/06/26 11:42:08" level=info prefix=APP
+//	caller=.../pkg/file.go:42 func=Handler msg="request accepted" id=7
+//
+// The Layouts of the output determine the caller keys: the file path
+// (short or full) with the line number as caller, the line number as
+// line if the file path is hidden, the function name as func and the
+// function address as addr. The timestamp is formatted according to
+// the TimestampFormat. The Space, WithColor and LevelFormat of the
+// output are ignored.
+type LogfmtFormatter struct{}
+
+// Format renders the record as a logfmt line.
+func (LogfmtFormatter) Format(r *Record, o *Output) ([]byte, error) {
+	return []byte(logfmtMessage(r, o)), nil
+}
+
+// The logfmtMessage creates a logfmt message.
+func logfmtMessage(r *Record, o *Output) string {
+	sb := strings.Builder{}
+	add := func(key, value string) {
+		if sb.Len() != 0 {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(logfmtKey(key))
+		sb.WriteByte('=')
+		sb.WriteString(logfmtValue(value))
+	}
+
+	// Header.
+	add("ts", r.Time.Format(o.TimestampFormat))
+	if v, ok := level.Labels[r.Level]; ok {
+		add("level", strings.ToLower(v))
+	}
+
+	if p := r.prefix(o); p != "" {
+		add("prefix", p)
+	}
+
+	// Caller.
+	// The FullPath takes precedence over ShortPath.
+	switch {
+	case o.Layouts.FilePath():
+		path := r.FilePath
+		if !o.Layouts.FullFilePath() {
+			path = cutFilePath(shortPathSections, path)
+		}
+
+		if o.Layouts.LineNumber() {
+			path += ":" + strconv.Itoa(r.LineNumber)
+		}
+
+		add("caller", path)
+	case o.Layouts.LineNumber():
+		add("line", strconv.Itoa(r.LineNumber))
+	}
+
+	if o.Layouts.FuncName() {
+		add("func", r.FuncName)
+	}
+
+	if o.Layouts.FuncAddress() {
+		add("addr", fmt.Sprintf("%#x", r.FuncAddress))
+	}
+
+	// Message and fields.
+	add("msg", r.Message)
+	for _, f := range r.Fields {
+		key := f.Key
+		if logfmtReservedKeys[key] {
+			key = "fields." + key
+		}
+
+		add(key, fmt.Sprint(fieldValue(f.Value)))
+	}
+
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// The logfmtKey returns the key suitable for logfmt: spaces, quotes,
+// equal signs and control characters are replaced with underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return badKey
+	}
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return '_'
+		}
+
+		return r
+	}, key)
+}
+
+// The logfmtValue returns the value suitable for logfmt: values
+// containing spaces, quotes, equal signs or control characters
+// are quoted, as well as the empty value.
+func logfmtValue(value string) string {
+	if needsQuoting(value) {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// TestLogfmtMessage tests logfmtMessage function.
+func TestLogfmtMessage(t *testing.T) {
+	r := &Record{
+		Prefix:      "APP",
+		Level:       level.Info,
+		Time:        time.Date(2023, 6, 26, 11, 42, 8, 0, time.UTC),
+		Message:     "request \"accepted\"\nnext",
+		FilePath:    "/home/user/app/pkg/file.go",
+		LineNumber:  42,
+		FuncName:    "Handler",
+		FuncAddress: 0x4a2f10,
+		Fields: []Field{
+			{Key: "user id", Value: 7},
+			{Key: "msg", Value: "dup"},
+			{Key: "err", Value: errors.New("timeout")},
+			{Key: "empty", Value: ""},
+		},
+	}
+
+	ts := " ts=2023-06-26T11:42:08Z level=info prefix=APP"
+	tail := ` msg="request \"accepted\"\nnext" user_id=7` +
+		` fields.msg=dup err=timeout empty=""` + "\n"
+
+	tests := []struct {
+		name    string
+		layouts layout.Layout
+		e       string
+	}{
+		{
+			name:    "Short path with line number",
+			layouts: layout.ShortFilePath | layout.LineNumber | layout.FuncName,
+			e:       ts + " caller=.../app/pkg/file.go:42 func=Handler" + tail,
+		},
+		{
+			name:    "Full path",
+			layouts: layout.FullFilePath,
+			e:       ts + " caller=/home/user/app/pkg/file.go" + tail,
+		},
+		{
+			name:    "Line number and function address",
+			layouts: layout.LineNumber | layout.FuncAddress,
+			e:       ts + " line=42 addr=0x4a2f10" + tail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Output{
+				Layouts:         tt.layouts,
+				WithPrefix:      trit.True,
+				TimestampFormat: time.RFC3339,
+			}
+
+			got := " " + logfmtMessage(r, o)
+			if got != tt.e {
+				t.Errorf("expected\n%q, got\n%q", tt.e, got)
+			}
+		})
+	}
+}
+
+// TestLogfmtFormatter tests the LogfmtFormatter of the output.
+func TestLogfmtFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New("APP")
+	logger.SetOutputs(Output{
+		Name:      "logfmt",
+		Writer:    buf,
+		Levels:    level.Default,
+		Layouts:   layout.ShortFilePath | layout.LineNumber,
+		Formatter: LogfmtFormatter{},
+	})
+
+	logger.Warnf("slow request %d", 42)
+	out := buf.String()
+	if !strings.HasPrefix(out, `ts="`) ||
+		!strings.Contains(out, " level=warning prefix=APP caller=") ||
+		!strings.Contains(out, "logfmt_test.go:") ||
+		!strings.HasSuffix(out, " msg=\"slow request 42\"\n") {
+		t.Errorf("incorrect message: %s", out)
+	}
+
+	// The trailing newline of the printf-like message isn't quoted.
+	buf.Reset()
+	logger.Infof("cache miss %s\n", "k")
+	out = buf.String()
+	if !strings.HasSuffix(out, ` msg="cache miss k"`+"\n") {
+		t.Errorf("incorrect message: %q", out)
+	}
+}
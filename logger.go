@@ -1,10 +1,13 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goloop/g"
@@ -51,6 +54,22 @@ const (
 	// The outLevelFormat is the default level format for the output.
 	outLevelFormat = "%s"
 
+	// The outAsync is default values for the output async parameters.
+	outAsync = trit.False
+
+	// The outBufferSize is the default size of the queue
+	// of the asynchronous output.
+	outBufferSize = 1024
+
+	// The outOverflow is the default overflow policy
+	// of the asynchronous output.
+	outOverflow = OverflowBlock
+
+	// The fatalFlushTimeout is the maximum time to wait for the
+	// asynchronous outputs to write their messages before the
+	// program is terminated by the Fatal* methods.
+	fatalFlushTimeout = 5 * time.Second
+
 	// There is a difference between text-style message formatting
 	// and JSON-style formatting.
 	//
@@ -99,6 +118,9 @@ var (
 		TextStyle:       outTextStyle,
 		TimestampFormat: outTimestampFormat,
 		LevelFormat:     outLevelFormat,
+		Async:           outAsync,
+		BufferSize:      outBufferSize,
+		Overflow:        outOverflow,
 	}
 
 	// Stderr standard rules for displaying logger errors
@@ -115,6 +137,9 @@ var (
 		TextStyle:       outTextStyle,
 		TimestampFormat: outTimestampFormat,
 		LevelFormat:     outLevelFormat,
+		Async:           outAsync,
+		BufferSize:      outBufferSize,
+		Overflow:        outOverflow,
 	}
 
 	// Default is output that processes all types of levels
@@ -131,6 +156,9 @@ var (
 		TextStyle:       outTextStyle,
 		TimestampFormat: outTimestampFormat,
 		LevelFormat:     outLevelFormat,
+		Async:           outAsync,
+		BufferSize:      outBufferSize,
+		Overflow:        outOverflow,
 	}
 
 	// The exit causes the current program to exit with the given status code.
@@ -240,6 +268,67 @@ type Output struct {
 	// the trit.True or trit.False value.
 	TextStyle trit.Trit
 
+	// Sampler is the sampler of the records of the output, it limits
+	// the number of repetitive records, see NewSampler. The sampler
+	// must not be shared between outputs.
+	//
+	// By default, the records are not sampled.
+	Sampler *Sampler
+
+	// RateLimit is the limit of the records (and/or bytes) per second
+	// written to the output, the records that exceed it are dropped,
+	// see RateLimit. Other outputs of the logger are not affected.
+	//
+	// By default, the output is not limited.
+	RateLimit RateLimit
+
+	// Filters are the rules that select the levels of the records by
+	// the place in the code where they were logged, e.g. to enable the
+	// Debug level for a single package. The first rule that matches the
+	// record replaces the Levels of the output, see Filter.
+	//
+	// By default, the Levels are used for all records.
+	Filters []Filter
+
+	// Fallback is the name of the output that receives the records this
+	// output failed to write, e.g. the stderr output for the file output.
+	// The record written to the fallback output has the FallbackField
+	// field with the name of the failed output. If the fallback output
+	// fails too, the record is passed to its fallback output, and so on.
+	// The fallback output writes the record regardless of its Levels.
+	// The fallbacks cannot form a loop.
+	//
+	// By default, the failed records are lost (see SetErrorHandler).
+	Fallback string
+
+	// DisableAfter is the number of the consecutive write errors after
+	// which the output is disabled (Enabled is set to false). After the
+	// ReprobeAfter interval the output is enabled again, if the next
+	// write fails, the output is disabled again for the doubled interval.
+	//
+	// By default, it's zero and the output is never disabled.
+	DisableAfter int
+
+	// ReprobeAfter is the interval after which the output disabled
+	// by the write errors is enabled again. By default, it's 10s.
+	ReprobeAfter time.Duration
+
+	// Dedup enables the suppression of the consecutive repeated records
+	// of the output, i.e. the records with the same level, caller and
+	// message: the repeats are not written, and "message repeated N times"
+	// is written when a different record arrives, or after the Dedup
+	// interval since the first repeat.
+	//
+	// By default, it's zero and the repeated records are written.
+	Dedup time.Duration
+
+	// Formatter is the renderer of the records of the output, e.g.
+	// TextFormatter, JSONFormatter or a custom implementation of the
+	// Formatter interface. If it is specified, the TextStyle is ignored.
+	//
+	// By default, the formatter is selected by the TextStyle.
+	Formatter Formatter
+
 	// TimestampFormat is the format of the timestamp in the log-message.
 	// Must be specified in the format of the time.Format() function.
 	TimestampFormat string
@@ -251,6 +340,48 @@ type Output struct {
 	// format as "[%s]".
 	LevelFormat string
 
+	// Async is the flag that determines whether to write messages
+	// asynchronously. The message is formatted by the logging goroutine,
+	// but it is written to the Writer by the background goroutine, so a
+	// slow writer doesn't stall the goroutines that log.
+	//
+	// By default, the output is synchronous.
+	//
+	// Values are given by numerical marks, where:
+	//  - values less than zero are considered false;
+	//  - values greater than zero are considered true;
+	//  - the value set to 0 is considered the default value
+	//    (or don't change, for edit mode).
+	//
+	// We can also use the github.com/goloop/trit package and
+	// the trit.True or trit.False value.
+	//
+	// Use Logger.Flush or Logger.Close to wait until the queued
+	// messages are written.
+	Async trit.Trit
+
+	// BufferSize is the maximum number of messages in the queue
+	// of the asynchronous output. By default, it is 1024.
+	BufferSize int
+
+	// Overflow is the policy that determines what to do with a new
+	// message when the queue of the asynchronous output is full:
+	// OverflowBlock (by default), OverflowDropNewest or OverflowDropOldest.
+	// The number of dropped messages is returned by the Dropped method.
+	Overflow Overflow
+
+	// The queue is the queue of the asynchronous output.
+	queue *asyncQueue
+
+	// The limiter is the rate limiter of the output.
+	limiter *rateLimiter
+
+	// The errs is the error counter of the output.
+	errs *outputErrors
+
+	// The deduper suppresses the repeated records of the output.
+	deduper *deduper
+
 	// The isSystem is the flag that determines whether the output is system.
 	// For example, this can be for all F* functions (Ferror, Finfo etc.) that
 	// accept a target writer. Package generates a unique Output for them.
@@ -283,33 +414,121 @@ type Logger struct {
 	// at once, for example, to the console and to the log file.
 	outputs map[string]*Output
 
+	// The fields is the list of key/value pairs bound to the logger
+	// with the With method. They are added to each log-message.
+	fields []Field
+
+	// The extractors is the list of the functions that extract fields
+	// from the context for the *Context methods.
+	extractors []ContextExtractor
+
+	// The hooks is the list of the hooks fired for each record
+	// before it is written to the outputs.
+	hooks []Hook
+
+	// The hookErrorHandler handles errors returned by the hooks.
+	hookErrorHandler HookErrorHandler
+
+	// The sampler is the sampler of the messages of the logger.
+	sampler *Sampler
+
+	// The errorHandler handles the errors of the outputs, it's read
+	// without the lock by the goroutines of the asynchronous outputs.
+	errorHandler atomic.Pointer[ErrorHandler]
+
+	// The pendingErrors and pendingHookErrors are the lists of the
+	// errors of the outputs and of the hooks that wait for the handlers
+	// until the logger's lock is released (see handleErrors), the
+	// hasErrors is true if the lists aren't empty. The lists are
+	// protected by the emu.
+	pendingErrors     []outputError
+	pendingHookErrors []hookError
+	hasErrors         atomic.Bool
+	emu               sync.Mutex
+
+	// The name is the name of the named logger, the root is its root
+	// logger that owns the outputs, and the tree is the tree of the
+	// levels of the named loggers of the root. See Named.
+	name string
+	root *Logger
+	tree *levelTree
+
+	// The temporary is the list of the pending reverts of the
+	// temporary changes of the outputs, the escalations is the list
+	// of the active escalations of the outputs. They are protected
+	// by the tmu.
+	temporary   map[string]*tempChange
+	escalations map[string]*escalations
+	tmu         sync.Mutex
+
+	// The closed is the flag that determines whether the logger was
+	// closed by the Close method. The closed logger writes nothing.
+	closed bool
+
+	// The afterClose is the number of messages that were logged
+	// after the logger was closed.
+	afterClose atomic.Uint64
+
 	// The mu is the mutex for the log object.
 	mu sync.RWMutex
 }
 
 // Copy returns copy of the logger object.
+//
+// The copy of the named logger (see Named) is not named, it has its own
+// copy of the outputs of the root logger.
 func (logger *Logger) Copy() *Logger {
+	// Get outputs.
+	// The named logger uses the outputs of the root logger.
+	outputs := logger.base().Outputs()
+
 	// Lock the log object for change.
 	logger.mu.RLock()
 	defer logger.mu.RUnlock()
 
-	// Get outputs.
-	outputs := make([]Output, 0, len(logger.outputs))
-	for _, o := range logger.outputs {
-		outputs = append(outputs, *o)
-	}
-
 	instance := &Logger{
-		skipStackFrames: logger.skipStackFrames,
-		fatalStatusCode: logger.fatalStatusCode,
-		prefix:          logger.prefix,
-		outputs:         map[string]*Output{},
+		skipStackFrames:  logger.skipStackFrames,
+		fatalStatusCode:  logger.fatalStatusCode,
+		prefix:           logger.prefix,
+		outputs:          map[string]*Output{},
+		fields:           logger.fields,
+		extractors:       slices.Clip(logger.extractors),
+		hooks:            slices.Clip(logger.hooks),
+		hookErrorHandler: logger.hookErrorHandler,
+		sampler:          logger.sampler,
 	}
 
+	instance.errorHandler.Store(logger.base().errorHandler.Load())
 	instance.SetOutputs(outputs...)
 	return instance
 }
 
+// With returns a child logger that carries the specified key/value pairs
+// as fields of each log-message. The child has the fields of the parent
+// and the new fields, if the key is already bound, its value is replaced.
+//
+// The keys must be strings. A key that is not a string, or a key without
+// a value, is stored under the "!BADKEY" key.
+//
+// In JSON style the fields become top-level keys of the object, in text
+// style they are rendered as key=value after the message.
+//
+// Example usage:
+//
+//	reqLogger := logger.With("request_id", id, "user_id", user.ID)
+//	reqLogger.Info("request accepted")
+//	// 2023/06/26 11:42:08 INFO ... request accepted request_id=42 user_id=7
+//
+// The child shares the outputs with the root logger like the named
+// logger (see Named): the changes of the outputs and the Close of the
+// parent are applied to the child. The child of the named logger has
+// the same name.
+func (logger *Logger) With(kv ...any) *Logger {
+	instance := logger.Named("")
+	instance.fields = mergeFields(instance.fields, makeFields(kv...))
+	return instance
+}
+
 // SetSkipStackFrames sets the number of stack frames to skip before
 // the program counter stack is collected.
 //
@@ -426,6 +645,11 @@ func (logger *Logger) Prefix() string {
 //	// Try to update with incorrect data.
 //	logger.SetOutputs(log.Output{}) // error: the 0 object has empty name
 func (logger *Logger) SetOutputs(outputs ...Output) error {
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.SetOutputs(outputs...)
+	}
+
 	// Lock the logger.
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
@@ -472,11 +696,36 @@ func (logger *Logger) SetOutputs(outputs ...Output) error {
 		o.TextStyle = g.Value(o.TextStyle, outTextStyle)
 		o.TimestampFormat = g.Value(o.TimestampFormat, outTimestampFormat)
 		o.LevelFormat = g.Value(o.LevelFormat, outLevelFormat)
+		o.Async = g.Value(o.Async, outAsync)
+		o.BufferSize = g.Value(o.BufferSize, outBufferSize)
+		o.Overflow = g.Value(o.Overflow, outOverflow)
 
 		result[o.Name] = o
 	}
 
+	if err := checkFallbacks(result); err != nil {
+		return err
+	}
+
+	// Start the queues of the asynchronous outputs and close
+	// the queues of the outputs that are no longer used.
+	queues := make(map[*asyncQueue]bool, len(result))
+	for _, o := range result {
+		o.syncQueue()
+		o.syncLimiter()
+		o.syncDeduper()
+		o.syncErrors(logger)
+		queues[o.queue] = true
+	}
+
+	for _, o := range logger.outputs {
+		if o.queue != nil && !queues[o.queue] {
+			go o.queue.close(context.Background())
+		}
+	}
+
 	logger.outputs = result
+	logger.closed = false // the new outputs open the closed logger
 	return nil
 }
 
@@ -498,47 +747,128 @@ func (logger *Logger) SetOutputs(outputs ...Output) error {
 //	    Levels:  log.PanicLevel|log.FatalLevel|log.ErrorLevel,
 //	})
 func (logger *Logger) EditOutputs(outputs ...Output) error {
-	// Lock the logger.
-	logger.mu.Lock()
-	defer logger.mu.Unlock()
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.EditOutputs(outputs...)
+	}
 
 	if len(outputs) == 0 {
 		return fmt.Errorf("the outputs list is empty")
 	}
 
-	// Check the correctness of the data and create a temporary map.
-	// If the data is not correct, we cannot change the data already
-	// set previously.
-	result := make(map[string]*Output, len(outputs))
-	for _, o := range outputs {
-		out, ok := logger.outputs[o.Name]
-		if !ok {
-			return fmt.Errorf("output not found '%s'", o.Name)
+	return logger.updateOutputs(func(result map[string]*Output) error {
+		// The changes are applied to the copy of the output,
+		// the same output can be edited several times.
+		edited := make(map[string]bool, len(outputs))
+		for _, o := range outputs {
+			current, ok := result[o.Name]
+			if !ok {
+				return fmt.Errorf("output not found '%s'", o.Name)
+			}
+
+			if !edited[o.Name] {
+				out := *current
+				current = &out
+				result[o.Name] = current
+				edited[o.Name] = true
+			}
+
+			current.merge(o)
 		}
 
-		// Set the new value if it is specified, otherwise leave the old one.
-		//
-		// Note: g.Value returns the first non-empty value.
-		out.Writer = g.Value(o.Writer, out.Writer)
-		out.Layouts = g.Value(o.Layouts, out.Layouts)
-		out.Levels = g.Value(o.Levels, out.Levels)
+		return nil
+	})
+}
+
+// The merge sets the parameters specified in the edit to the output,
+// the empty parameters of the edit don't change the output.
+func (o *Output) merge(edit Output) {
+	// Note: g.Value returns the first non-empty value.
+	o.Writer = g.Value(edit.Writer, o.Writer)
+	o.Layouts = g.Value(edit.Layouts, o.Layouts)
+	o.Levels = g.Value(edit.Levels, o.Levels)
+
+	o.Space = g.Value(edit.Space, o.Space)
+	o.WithPrefix = g.Value(edit.WithPrefix, o.WithPrefix)
+	o.WithColor = g.Value(edit.WithColor, o.WithColor)
+	o.Enabled = g.Value(edit.Enabled, o.Enabled)
+	o.TextStyle = g.Value(edit.TextStyle, o.TextStyle)
+	if edit.Formatter != nil {
+		o.Formatter = edit.Formatter
+	}
+
+	if edit.Sampler != nil {
+		o.Sampler = edit.Sampler
+	}
 
-		out.Space = g.Value(o.Space, out.Space)
-		out.WithPrefix = g.Value(o.WithPrefix, out.WithPrefix)
-		out.WithColor = g.Value(o.WithColor, out.WithColor)
-		out.Enabled = g.Value(o.Enabled, out.Enabled)
-		out.TextStyle = g.Value(o.TextStyle, out.TextStyle)
-		out.TimestampFormat = g.Value(o.TimestampFormat, out.TimestampFormat)
-		out.LevelFormat = g.Value(o.LevelFormat, out.LevelFormat)
+	if edit.Filters != nil {
+		o.Filters = edit.Filters
+	}
 
-		result[o.Name] = out
+	if edit.RateLimit != (RateLimit{}) {
+		o.RateLimit = edit.RateLimit
 	}
 
-	// Update outputs.
+	o.TimestampFormat = g.Value(edit.TimestampFormat, o.TimestampFormat)
+	o.LevelFormat = g.Value(edit.LevelFormat, o.LevelFormat)
+	o.Async = g.Value(edit.Async, o.Async)
+	o.BufferSize = g.Value(edit.BufferSize, o.BufferSize)
+	o.Overflow = g.Value(edit.Overflow, o.Overflow)
+	o.Dedup = g.Value(edit.Dedup, o.Dedup)
+	o.Fallback = g.Value(edit.Fallback, o.Fallback)
+	o.DisableAfter = g.Value(edit.DisableAfter, o.DisableAfter)
+	o.ReprobeAfter = g.Value(edit.ReprobeAfter, o.ReprobeAfter)
+}
+
+// The updateOutputs changes the outputs all at once or not at all. The
+// update gets the copy of the list of outputs: it adds, deletes or
+// replaces the outputs, the changed output must be replaced by its
+// copy. If the update returns an error or the fallbacks of the result
+// are incorrect (see checkFallbacks), the outputs aren't changed.
+func (logger *Logger) updateOutputs(
+	update func(outputs map[string]*Output) error,
+) error {
+	// The named logger uses the outputs of the root logger.
+	logger = logger.base()
+
+	// Lock the logger.
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	result := make(map[string]*Output, len(logger.outputs))
+	for n, o := range logger.outputs {
+		result[n] = o
+	}
+
+	if err := update(result); err != nil {
+		return err
+	}
+
+	if err := checkFallbacks(result); err != nil {
+		return err
+	}
+
+	// Synchronize the state of the changed outputs and close
+	// the queues of the outputs that are no longer used.
+	queues := make(map[*asyncQueue]bool, len(result))
 	for n, o := range result {
-		logger.outputs[n] = o
+		if logger.outputs[n] != o {
+			o.syncQueue()
+			o.syncLimiter()
+			o.syncDeduper()
+			o.syncErrors(logger)
+		}
+
+		queues[o.queue] = true
+	}
+
+	for _, o := range logger.outputs {
+		if o.queue != nil && !queues[o.queue] {
+			go o.queue.close(context.Background())
+		}
 	}
 
+	logger.outputs = result
 	return nil
 }
 
@@ -549,11 +879,21 @@ func (logger *Logger) EditOutputs(outputs ...Output) error {
 //	// Delete the "stdout" output.
 //	logger.DeleteOutputs(log.Stdout.Name)
 func (logger *Logger) DeleteOutputs(names ...string) {
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		logger.root.DeleteOutputs(names...)
+		return
+	}
+
 	// Lock the logger.
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 
 	for _, name := range names {
+		if o, ok := logger.outputs[name]; ok && o.queue != nil {
+			go o.queue.close(context.Background())
+		}
+
 		delete(logger.outputs, name)
 	}
 }
@@ -571,6 +911,11 @@ func (logger *Logger) DeleteOutputs(names ...string) {
 //	// Set new outputs.
 //	logger.SetOutputs(outputs...)
 func (logger *Logger) Outputs(names ...string) []Output {
+	// The named logger uses the outputs of the root logger.
+	if logger.root != nil {
+		return logger.root.Outputs(names...)
+	}
+
 	logger.mu.RLock()
 	defer logger.mu.RUnlock()
 
@@ -596,47 +941,210 @@ func (logger *Logger) Outputs(names ...string) []Output {
 }
 
 // The echo is universal method creates a message of the fmt.Fprint format.
-func (logger *Logger) echo(w io.Writer, l level.Level, f string, a ...any) {
+// The kv is the list of the key/value pairs of the current call, they are
+// added to the fields bound to the logger. The ctx is the context of the
+// *Context methods, it's passed to the slog outputs, it can be nil.
+func (logger *Logger) echo(
+	ctx context.Context,
+	w io.Writer,
+	l level.Level,
+	kv []any,
+	f string,
+	a ...any,
+) {
+	// The errors of the outputs are handled after the lock is released.
+	defer logger.base().handleErrors()
+
 	// Lock the log object for change.
 	logger.mu.RLock()
 	defer logger.mu.RUnlock()
 
+	// The levels of the named logger.
+	if !logger.enabled(l) {
+		return
+	}
+
+	// The message that no output can accept is dropped
+	// before the stack frame and the record are created.
+	if w == nil && len(logger.hooks) == 0 && !logger.accepts(l) {
+		return
+	}
+
 	// Get the stack frame.
 	sf := getStackFrame(logger.skipStackFrames)
 
+	// The sampler of the logger can suppress the message
+	// before it's formatted.
+	if logger.sampler != nil && !logger.sample(l, f, sf) {
+		return
+	}
+
+	// Fields of the logger, fields of the context
+	// and fields of the current call.
+	cf := makeFields(logger.contextFields(ctx)...)
+	fields := mergeFields(mergeFields(logger.fields, cf), makeFields(kv...))
+	r := newRecord(logger.prefix, l, sf, fields, f, a)
+	r.ctx, r.contextKeys = ctx, fieldKeys(cf)
+	logger.emit(w, r)
+}
+
+// The accepts returns false if no enabled output of the logger
+// accepts the level by its levels or the levels of its filters.
+//
+// The method must be called with the logger's read lock held,
+// the read lock of the root of the named logger is taken here.
+func (logger *Logger) accepts(l level.Level) bool {
+	base := logger
+	if logger.root != nil {
+		base = logger.root
+		base.mu.RLock()
+		defer base.mu.RUnlock()
+	}
+
+	// The closed logger counts the messages, see emit.
+	if base.closed {
+		return true
+	}
+
+	for _, o := range base.outputs {
+		levels := o.Levels
+		for i := range o.Filters {
+			levels |= o.Filters[i].Levels
+		}
+
+		if levels&l == l && o.Enabled.IsTrue() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The emit fires the hooks for the record and writes it to all
+// outputs that accept its level.
+//
+// The method must be called with the logger's read lock held,
+// the read lock of the root of the named logger is taken here.
+func (logger *Logger) emit(w io.Writer, r *Record) {
+	// The named logger writes the record to the outputs of the root
+	// logger, the hooks of the named logger are fired.
+	base := logger
+	if logger.root != nil {
+		base = logger.root
+		base.mu.RLock()
+		defer base.mu.RUnlock()
+	}
+
+	// The closed logger writes nothing.
+	if base.closed {
+		base.afterClose.Add(1)
+		return
+	}
+
+	// The hooks can change or veto the record.
+	if !logger.fire(r) {
+		return
+	}
+
 	// If an additional value is set for the output (writer),
 	// use it with the default settings.
-	outputs := logger.outputs
+	//
+	// Note: the system output is added to the copy of the list
+	// of outputs, it's used for the current message only.
+	outputs := base.outputs
 	if w != nil {
 		output := Default
 		output.Writer = w
 		output.isSystem = true
+
+		outputs = make(map[string]*Output, len(base.outputs)+1)
+		for n, o := range base.outputs {
+			outputs[n] = o
+		}
 		outputs["*"] = &output // this name can be used for system names
 	}
 
 	// Output message.
-	for _, o := range logger.outputs {
-		var msg string
-		has, err := o.Levels.Contains(l)
+	for name, o := range outputs {
+		// The filters of the output select the levels by the
+		// caller of the record, before the record is formatted.
+		levels := o.levels(r)
+		has, err := levels.Contains(r.Level)
 		if !has || err != nil || !o.Enabled.IsTrue() {
 			continue
 		}
 
-		// Hide or show the prefix.
-		prefix := logger.prefix
-		if !o.WithPrefix.IsTrue() {
-			prefix = ""
+		// The sampler of the output can suppress the record,
+		// except the reports generated by the logger.
+		if o.Sampler != nil && !r.synthetic &&
+			!base.sampleOutput(name, o, r) {
+			continue
+		}
+
+		// The repeats of the last record of the output are suppressed.
+		if o.deduper != nil && !base.dedup(name, o, r) {
+			continue
+		}
+
+		base.write(name, o, r)
+	}
+}
+
+// The write writes the record to the output.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) write(name string, o *Output, r *Record) {
+	// The records that exceed the rate limit of the output are dropped.
+	if logger.rateLimited(name, o, r, nil) {
+		return
+	}
+
+	r.render()
+
+	// The output passes the message to the slog.Handler.
+	if sw, ok := o.Writer.(*slogWriter); ok {
+		f, a := r.args()
+		err := sw.emit(r.context(), r.prefix(o), r.Level, r.Time,
+			r.stackFrame(), r.Fields, f, a)
+		logger.written(name, o, err)
+		if err != nil {
+			logger.fallback(name, o, r)
 		}
 
-		// Text or JSON representation of the message.
-		if o.TextStyle.IsTrue() {
-			msg = textMessage(prefix, l, time.Now(), o, sf, f, a...)
+		return
+	}
+
+	// Text, JSON or custom representation of the message.
+	data, err := o.formatter().Format(r, o)
+	if err != nil {
+		if o.errs != nil {
+			o.errs.fail(err, false)
 		} else {
-			msg = objectMessage(prefix, l, time.Now(), o, sf, f, a...)
+			logger.reportError(name, err)
 		}
 
-		// Print message.
-		fmt.Fprint(o.Writer, msg)
+		return
+	}
+
+	if o.RateLimit.Bytes > 0 && logger.rateLimited(name, o, r, data) {
+		return
+	}
+
+	// Print message.
+	// The asynchronous output passes the message to its queue.
+	// The asynchronous output passes the record to the fallback
+	// output from the goroutine of the queue.
+	var fr *Record
+	if o.Fallback != "" {
+		fr = r
+	}
+
+	if o.queue == nil || !o.queue.push(o.Writer, data, o.errs, fr) {
+		err := writeData(o.Writer, data)
+		logger.written(name, o, err)
+		if err != nil {
+			logger.fallback(name, o, r)
+		}
 	}
 }
 
@@ -644,14 +1152,16 @@ func (logger *Logger) echo(w io.Writer, l level.Level, f string, a ...any) {
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Fpanic(w io.Writer, a ...any) {
-	logger.echo(w, level.Panic, formatPrint, a...)
+	logger.echo(nil, w, level.Panic, nil, formatPrint, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprint(a...))
 }
 
 // Fpanicf creates message with Panic level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Fpanicf(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Panic, format, a...)
+	logger.echo(nil, w, level.Panic, nil, format, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprintf(format, a...))
 }
 
@@ -659,7 +1169,8 @@ func (logger *Logger) Fpanicf(w io.Writer, format string, a ...any) {
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Fpanicln(w io.Writer, a ...any) {
-	logger.echo(w, level.Panic, formatPrintln, a...)
+	logger.echo(nil, w, level.Panic, nil, formatPrintln, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprintln(a...))
 }
 
@@ -667,14 +1178,16 @@ func (logger *Logger) Fpanicln(w io.Writer, a ...any) {
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Panic(a ...any) {
-	logger.echo(nil, level.Panic, formatPrint, a...)
+	logger.echo(nil, nil, level.Panic, nil, formatPrint, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprint(a...))
 }
 
 // Panicf creates message with Panic level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Panicf(format string, a ...any) {
-	logger.echo(nil, level.Panic, format, a...)
+	logger.echo(nil, nil, level.Panic, nil, format, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprintf(format, a...))
 }
 
@@ -682,253 +1195,306 @@ func (logger *Logger) Panicf(format string, a ...any) {
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Panicln(a ...any) (int, error) {
-	logger.echo(nil, level.Panic, formatPrintln, a...)
+	logger.echo(nil, nil, level.Panic, nil, formatPrintln, a...)
+	logger.flushWithTimeout()
 	panic(fmt.Sprintln(a...))
 }
 
+// Panicw creates message with Panic level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Panicw(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Panic, kv, formatPrintln, msg)
+	logger.flushWithTimeout()
+	panic(msg)
+}
+
 // Ffatal creates message with Fatal level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Ffatal(w io.Writer, a ...any) {
-	logger.echo(w, level.Fatal, formatPrint, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, w, level.Fatal, nil, formatPrint, a...)
+	logger.terminate()
 }
 
 // Ffatalf creates message with Fatal level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Ffatalf(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Fatal, format, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, w, level.Fatal, nil, format, a...)
+	logger.terminate()
 }
 
 // Ffatalln creates message with Fatal level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Ffatalln(w io.Writer, a ...any) {
-	logger.echo(w, level.Fatal, formatPrintln, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, w, level.Fatal, nil, formatPrintln, a...)
+	logger.terminate()
 }
 
 // Fatal creates message with Fatal level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Fatal(a ...any) {
-	logger.echo(nil, level.Fatal, formatPrint, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, nil, level.Fatal, nil, formatPrint, a...)
+	logger.terminate()
 }
 
 // Fatalf creates message with Fatal level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Fatalf(format string, a ...any) {
-	logger.echo(nil, level.Fatal, format, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, nil, level.Fatal, nil, format, a...)
+	logger.terminate()
 }
 
 // Fatalln creates message with Fatal, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Fatalln(a ...any) {
-	logger.echo(nil, level.Fatal, formatPrintln, a...)
-	exit(logger.fatalStatusCode)
+	logger.echo(nil, nil, level.Fatal, nil, formatPrintln, a...)
+	logger.terminate()
+}
+
+// Fatalw creates message with Fatal level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Fatalw(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Fatal, kv, formatPrintln, msg)
+	logger.terminate()
 }
 
 // Ferror creates message with Error level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Ferror(w io.Writer, a ...any) {
-	logger.echo(w, level.Error, formatPrint, a...)
+	logger.echo(nil, w, level.Error, nil, formatPrint, a...)
 }
 
 // Ferrorf creates message with Error level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Ferrorf(w io.Writer, f string, a ...any) {
-	logger.echo(w, level.Error, f, a...)
+	logger.echo(nil, w, level.Error, nil, f, a...)
 }
 
 // Ferrorln creates message with Error level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Ferrorln(w io.Writer, a ...any) {
-	logger.echo(w, level.Error, formatPrintln, a...)
+	logger.echo(nil, w, level.Error, nil, formatPrintln, a...)
 }
 
 // Error creates message with Error level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Error(a ...any) {
-	logger.echo(nil, level.Error, formatPrint, a...)
+	logger.echo(nil, nil, level.Error, nil, formatPrint, a...)
 }
 
 // Errorf creates message with Error level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Errorf(f string, a ...any) {
-	logger.echo(nil, level.Error, f, a...)
+	logger.echo(nil, nil, level.Error, nil, f, a...)
 }
 
 // Errorln creates message with Error, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Errorln(a ...any) {
-	logger.echo(nil, level.Error, formatPrintln, a...)
+	logger.echo(nil, nil, level.Error, nil, formatPrintln, a...)
+}
+
+// Errorw creates message with Error level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Errorw(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Error, kv, formatPrintln, msg)
 }
 
 // Fwarn creates message with Warn level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Fwarn(w io.Writer, a ...any) {
-	logger.echo(w, level.Warn, formatPrint, a...)
+	logger.echo(nil, w, level.Warn, nil, formatPrint, a...)
 }
 
 // Fwarnf creates message with Warn level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Fwarnf(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Warn, format, a...)
+	logger.echo(nil, w, level.Warn, nil, format, a...)
 }
 
 // Fwarnln creates message with Warn level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Fwarnln(w io.Writer, a ...any) {
-	logger.echo(w, level.Warn, formatPrintln, a...)
+	logger.echo(nil, w, level.Warn, nil, formatPrintln, a...)
 }
 
 // Warn creates message with Warn level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Warn(a ...any) {
-	logger.echo(nil, level.Warn, formatPrint, a...)
+	logger.echo(nil, nil, level.Warn, nil, formatPrint, a...)
 }
 
 // Warnf creates message with Warn level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Warnf(format string, a ...any) {
-	logger.echo(nil, level.Warn, format, a...)
+	logger.echo(nil, nil, level.Warn, nil, format, a...)
 }
 
 // Warnln creates message with Warn, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Warnln(a ...any) {
-	logger.echo(nil, level.Warn, formatPrintln, a...)
+	logger.echo(nil, nil, level.Warn, nil, formatPrintln, a...)
+}
+
+// Warnw creates message with Warn level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Warnw(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Warn, kv, formatPrintln, msg)
 }
 
 // Finfo creates message with Info level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Finfo(w io.Writer, a ...any) {
-	logger.echo(w, level.Info, formatPrint, a...)
+	logger.echo(nil, w, level.Info, nil, formatPrint, a...)
 }
 
 // Finfof creates message with Info level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Finfof(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Info, format, a...)
+	logger.echo(nil, w, level.Info, nil, format, a...)
 }
 
 // Finfoln creates message with Info level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Finfoln(w io.Writer, a ...any) {
-	logger.echo(w, level.Info, formatPrintln, a...)
+	logger.echo(nil, w, level.Info, nil, formatPrintln, a...)
 }
 
 // Info creates message with Info level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Info(a ...any) {
-	logger.echo(nil, level.Info, formatPrint, a...)
+	logger.echo(nil, nil, level.Info, nil, formatPrint, a...)
 }
 
 // Infof creates message with Info level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Infof(format string, a ...any) {
-	logger.echo(nil, level.Info, format, a...)
+	logger.echo(nil, nil, level.Info, nil, format, a...)
 }
 
 // Infoln creates message with Info, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Infoln(a ...any) {
-	logger.echo(nil, level.Info, formatPrintln, a...)
+	logger.echo(nil, nil, level.Info, nil, formatPrintln, a...)
+}
+
+// Infow creates message with Info level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Infow(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Info, kv, formatPrintln, msg)
 }
 
 // Fdebug creates message with Debug level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Fdebug(w io.Writer, a ...any) {
-	logger.echo(w, level.Debug, formatPrint, a...)
+	logger.echo(nil, w, level.Debug, nil, formatPrint, a...)
 }
 
 // Fdebugf creates message with Debug level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Fdebugf(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Debug, format, a...)
+	logger.echo(nil, w, level.Debug, nil, format, a...)
 }
 
 // Fdebugln creates message with Debug level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Fdebugln(w io.Writer, a ...any) {
-	logger.echo(w, level.Debug, formatPrintln, a...)
+	logger.echo(nil, w, level.Debug, nil, formatPrintln, a...)
 }
 
 // Debug creates message with Debug level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Debug(a ...any) {
-	logger.echo(nil, level.Debug, formatPrint, a...)
+	logger.echo(nil, nil, level.Debug, nil, formatPrint, a...)
 }
 
 // Debugf creates message with Debug level, according to a format specifier
 // and writes to log.Writer. It returns the number of bytes written and any
 // write error encountered.
 func (logger *Logger) Debugf(format string, a ...any) {
-	logger.echo(nil, level.Debug, format, a...)
+	logger.echo(nil, nil, level.Debug, nil, format, a...)
 }
 
 // Debugln creates message with Debug, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Debugln(a ...any) {
-	logger.echo(nil, level.Debug, formatPrintln, a...)
+	logger.echo(nil, nil, level.Debug, nil, formatPrintln, a...)
+}
+
+// Debugw creates message with Debug level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Debugw(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Debug, kv, formatPrintln, msg)
 }
 
 // Ftrace creates message with Trace level, using the default formats
 // for its operands and writes to w. Spaces are added between operands
 // when neither is a string.
 func (logger *Logger) Ftrace(w io.Writer, a ...any) {
-	logger.echo(w, level.Trace, formatPrint, a...)
+	logger.echo(nil, w, level.Trace, nil, formatPrint, a...)
 }
 
 // Ftracef creates message with Trace level, according to a format
 // specifier and writes to w.
 func (logger *Logger) Ftracef(w io.Writer, format string, a ...any) {
-	logger.echo(w, level.Trace, format, a...)
+	logger.echo(nil, w, level.Trace, nil, format, a...)
 }
 
 // Ftraceln creates message with Trace level, using the default formats
 // for its operands and writes to w. Spaces are always added between
 // operands and a newline is appended.
 func (logger *Logger) Ftraceln(w io.Writer, a ...any) {
-	logger.echo(w, level.Trace, formatPrintln, a...)
+	logger.echo(nil, w, level.Trace, nil, formatPrintln, a...)
 }
 
 // Trace creates message with Trace level, using the default formats
 // for its operands and writes to log.Writer. Spaces are added between
 // operands when neither is a string.
 func (logger *Logger) Trace(a ...any) {
-	logger.echo(nil, level.Trace, formatPrint, a...)
+	logger.echo(nil, nil, level.Trace, nil, formatPrint, a...)
 }
 
 // Tracef creates message with Trace level, according to a format specifier
 // and writes to log.Writer.
 func (logger *Logger) Tracef(format string, a ...any) {
-	logger.echo(nil, level.Trace, format, a...)
+	logger.echo(nil, nil, level.Trace, nil, format, a...)
 }
 
 // Traceln creates message with Trace, level using the default formats
 // for its operands and writes to log.Writer. Spaces are always added
 // between operands and a newline is appended.
 func (logger *Logger) Traceln(a ...any) {
-	logger.echo(nil, level.Trace, formatPrintln, a...)
+	logger.echo(nil, nil, level.Trace, nil, formatPrintln, a...)
+}
+
+// Tracew creates message with Trace level and writes to log.Writer.
+// The kv is the list of alternating keys and values that are added
+// to the message as fields (see With). A newline is appended.
+func (logger *Logger) Tracew(msg string, kv ...any) {
+	logger.echo(nil, nil, level.Trace, kv, formatPrintln, msg)
 }
@@ -1,6 +1,8 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
@@ -25,7 +27,7 @@ func TestEcho(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	logger.echo(nil, level.Debug, "test %s", "message")
+	logger.echo(nil, nil, level.Debug, nil, "test %s", "message")
 	outC := make(chan string)
 	go ioCopy(r, outC)
 	w.Close()
@@ -44,7 +46,7 @@ func TestEcho(t *testing.T) {
 		WithPrefix: trit.False,
 	})
 
-	logger.echo(nil, level.Debug, "test %s", "message")
+	logger.echo(nil, nil, level.Debug, nil, "test %s", "message")
 	outC = make(chan string)
 	go ioCopy(r, outC)
 	w.Close()
@@ -63,7 +65,7 @@ func TestEcho(t *testing.T) {
 		TextStyle: trit.False,
 	})
 
-	logger.echo(nil, level.Debug, "test %s", "message")
+	logger.echo(nil, nil, level.Debug, nil, "test %s", "message")
 	outC = make(chan string)
 	go ioCopy(r, outC)
 	w.Close()
@@ -81,7 +83,7 @@ func TestEcho(t *testing.T) {
 		Enabled: trit.False,
 	})
 
-	logger.echo(nil, level.Debug, "test %s", "message")
+	logger.echo(nil, nil, level.Debug, nil, "test %s", "message")
 	outC = make(chan string)
 	go ioCopy(r, outC)
 	w.Close()
@@ -137,7 +139,7 @@ func TestEchoWithTextFormatting(t *testing.T) {
 				Levels: level.Default,
 			})
 
-			logger.echo(nil, level.Debug, tt.format, tt.in...)
+			logger.echo(nil, nil, level.Debug, nil, tt.format, tt.in...)
 			outC := make(chan string)
 			go ioCopy(r, outC)
 			w.Close()
@@ -195,7 +197,7 @@ func TestEchoWithJSONFormatting(t *testing.T) {
 				TextStyle: trit.False,
 			})
 
-			logger.echo(nil, level.Debug, tt.format, tt.in...)
+			logger.echo(nil, nil, level.Debug, nil, tt.format, tt.in...)
 			outC := make(chan string)
 			go ioCopy(r, outC)
 			w.Close()
@@ -210,3 +212,126 @@ func TestEchoWithJSONFormatting(t *testing.T) {
 //
 // The others of the method is rolled through global function, see log_test.go
 //
+
+// TestWith tests the With method and the *w methods of the Logger.
+func TestWith(t *testing.T) {
+	logger := New()
+	logger.SetSkipStackFrames(2)
+	buf := &bytes.Buffer{}
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+
+	child := logger.With("request_id", 42)
+	child.Infow("request accepted", "user", "John Doe")
+
+	out := buf.String()
+	want := `request accepted request_id=42 user="John Doe"` + "\n"
+	if !strings.HasSuffix(out, want) {
+		t.Errorf("expected `%s` in `%s`", want, out)
+	}
+
+	// The parent logger has no fields.
+	buf.Reset()
+	logger.Infoln("plain")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("the parent logger has fields: %s", buf.String())
+	}
+
+	// JSON style.
+	buf.Reset()
+	child.EditOutputs(Output{Name: "test", TextStyle: trit.False})
+	child.Errorw("failed", "user_id", 7)
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if obj["message"] != "failed" ||
+		obj["request_id"] != float64(42) ||
+		obj["user_id"] != float64(7) {
+		t.Errorf("incorrect JSON message: %s", buf.String())
+	}
+}
+
+// TestWithSharedOutputs tests that the child logger
+// shares the outputs with its parent.
+func TestWithSharedOutputs(t *testing.T) {
+	logger := New()
+	buf := &bytes.Buffer{}
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Error,
+	})
+
+	child := logger.With("request_id", 42)
+
+	// The changes of the parent's outputs are applied to the child.
+	logger.EditOutputs(Output{Name: "test", Levels: level.Default})
+	child.Debug("visible")
+	if !strings.Contains(buf.String(), "visible") {
+		t.Errorf("the child doesn't use the edited output: %q", buf.String())
+	}
+
+	// The child of the closed parent writes nothing.
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	child.Error("after close")
+	if buf.Len() != 0 || logger.DroppedAfterClose() != 1 ||
+		child.DroppedAfterClose() != 1 {
+		t.Errorf("the child writes after close: %q, %d", buf.String(),
+			logger.DroppedAfterClose())
+	}
+}
+
+// The countingStringer counts the calls of its String method.
+type countingStringer struct {
+	calls int
+}
+
+// String returns the string representation of the value.
+func (s *countingStringer) String() string {
+	s.calls++
+	return "value"
+}
+
+// TestLazyFormatting tests that the message is formatted only
+// if the record is accepted, and only once.
+func TestLazyFormatting(t *testing.T) {
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(
+		Output{
+			Name:    "first",
+			Writer:  first,
+			Levels:  level.Error,
+			Filters: []Filter{{Package: "internal/billing", Levels: level.Debug}},
+		},
+		Output{Name: "second", Writer: second, Levels: level.Error},
+	)
+
+	s := &countingStringer{}
+	logger.Debugf("debug %s", s) // rejected by the filter
+	logger.Infof("info %s", s)   // rejected by the levels
+	if s.calls != 0 {
+		t.Errorf("the rejected messages were formatted %d times", s.calls)
+	}
+
+	logger.Errorf("error %s", s)
+	if s.calls != 1 {
+		t.Errorf("expected 1 formatting, got %d", s.calls)
+	}
+
+	for _, buf := range []*bytes.Buffer{first, second} {
+		if !strings.Contains(buf.String(), "error value") {
+			t.Errorf("incorrect output: %s", buf)
+		}
+	}
+}
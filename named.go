@@ -0,0 +1,245 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goloop/log/level"
+)
+
+// The levelRule sets the levels of the named loggers
+// whose names match the pattern.
+type levelRule struct {
+	pattern string
+	levels  level.Level
+}
+
+// The levelTree is the tree of the levels of the named loggers, it's
+// shared by the root logger and all its named loggers. The levels of
+// the logger are set by the last rule that matches its name, or, if
+// there is no such rule, they are inherited from the parent logger.
+type levelTree struct {
+	rules []levelRule
+	cache map[string]level.Level // effective levels by the name
+	mu    sync.RWMutex
+}
+
+// The set sets the levels of the loggers that match the pattern.
+func (t *levelTree) set(pattern string, levels level.Level) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The rule that is set again becomes the last one.
+	t.rules = slices.DeleteFunc(t.rules, func(r levelRule) bool {
+		return r.pattern == pattern
+	})
+	t.rules = append(t.rules, levelRule{pattern: pattern, levels: levels})
+	clear(t.cache)
+}
+
+// The reset removes the rule of the pattern, it returns
+// false if the rule of the pattern is not set.
+func (t *levelTree) reset(pattern string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.rules)
+	t.rules = slices.DeleteFunc(t.rules, func(r levelRule) bool {
+		return r.pattern == pattern
+	})
+	clear(t.cache)
+	return len(t.rules) != n
+}
+
+// The levels returns the effective levels of the logger with the name.
+func (t *levelTree) levels(name string) level.Level {
+	t.mu.RLock()
+	levels, ok := t.cache[name]
+	t.mu.RUnlock()
+	if ok {
+		return levels
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	levels = t.lookup(name)
+	if t.cache == nil {
+		t.cache = make(map[string]level.Level)
+	}
+
+	t.cache[name] = levels
+	return levels
+}
+
+// The lookup finds the levels of the logger with the name: the
+// levels of the last rule that matches the name, or the parent
+// name, and so on up to the root.
+//
+// The method must be called with the tree's lock held.
+func (t *levelTree) lookup(name string) level.Level {
+	for n := name; ; {
+		for i := len(t.rules) - 1; i >= 0; i-- {
+			if ok, _ := path.Match(t.rules[i].pattern, n); ok {
+				return t.rules[i].levels
+			}
+		}
+
+		i := strings.LastIndex(n, ".")
+		if i < 0 {
+			break
+		}
+
+		n = n[:i]
+	}
+
+	return level.Default
+}
+
+// Named returns the named child of the default logger,
+// it shares the outputs with the default logger. See Logger.Named.
+func Named(name string) *Logger {
+	// The default logger works at the imported package level,
+	// the child logger is used directly.
+	logger := self.Named(name)
+	logger.SetSkipStackFrames(logger.SkipStackFrames() - 1)
+	return logger
+}
+
+// SetLevels sets the levels of the named loggers of the default
+// logger whose names match the pattern. See Logger.SetLevels.
+func SetLevels(pattern string, levels level.Level) error {
+	return self.SetLevels(pattern, levels)
+}
+
+// ResetLevels removes the rule of the pattern set by SetLevels.
+func ResetLevels(pattern string) bool {
+	return self.ResetLevels(pattern)
+}
+
+// Named returns the named child logger. The name of the child is
+// the name of the logger and the specified name joined by a dot,
+// e.g. logger.Named("app").Named("db") is named "app.db".
+//
+// The named loggers share the outputs with the root logger (the
+// logger that isn't named), so the files are not opened twice: the
+// changes of the outputs made by any of them are applied to all of
+// them. The prefix, fields and hooks are copied from the parent.
+//
+// The levels of the records of the named logger are selected by
+// SetLevels, in addition to the levels of the outputs.
+//
+// Example usage:
+//
+//	db := logger.Named("app").Named("db")
+//	pool := db.Named("pool") // app.db.pool
+//
+//	// Debug for the app.db and its children, Info for the rest.
+//	logger.SetLevels("app", level.AtLeast(level.Info))
+//	logger.SetLevels("app.db", level.AtLeast(level.Debug))
+func (logger *Logger) Named(name string) *Logger {
+	tree := logger.levelTree()
+
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	if logger.name != "" && name != "" {
+		name = logger.name + "." + name
+	} else if name == "" {
+		name = logger.name
+	}
+
+	return &Logger{
+		skipStackFrames:  logger.skipStackFrames,
+		fatalStatusCode:  logger.fatalStatusCode,
+		prefix:           logger.prefix,
+		fields:           logger.fields,
+		extractors:       slices.Clip(logger.extractors),
+		hooks:            slices.Clip(logger.hooks),
+		hookErrorHandler: logger.hookErrorHandler,
+		sampler:          logger.sampler,
+		name:             name,
+		root:             logger.base(),
+		tree:             tree,
+	}
+}
+
+// Name returns the name of the logger, see Named.
+// The root logger has an empty name.
+func (logger *Logger) Name() string {
+	return logger.name
+}
+
+// SetLevels sets the levels of the named loggers whose names match
+// the pattern, the pattern has the syntax of path.Match, for example:
+// "app.db" is the app.db logger, "app.db.*" is all its children.
+//
+// The named logger uses the levels of the last rule that matches its
+// name, if there is no such rule, it inherits the levels of its parent.
+// The records of the levels that aren't set are not written, regardless
+// of the levels of the outputs. By default, all levels are set.
+//
+// The rules are shared by the root logger and all its named loggers.
+func (logger *Logger) SetLevels(pattern string, levels level.Level) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+
+	logger.levelTree().set(pattern, levels)
+	return nil
+}
+
+// ResetLevels removes the rule of the pattern set by SetLevels, the
+// loggers inherit the levels of their parents again. It returns false
+// if the rule of the pattern is not set.
+func (logger *Logger) ResetLevels(pattern string) bool {
+	return logger.levelTree().reset(pattern)
+}
+
+// Levels returns the effective levels of the named logger, see SetLevels.
+// The root logger has all levels.
+func (logger *Logger) Levels() level.Level {
+	if logger.name == "" {
+		return level.Default
+	}
+
+	return logger.tree.levels(logger.name)
+}
+
+// The base returns the root logger of the named logger,
+// or the logger itself if it isn't named.
+func (logger *Logger) base() *Logger {
+	if logger.root != nil {
+		return logger.root
+	}
+
+	return logger
+}
+
+// The levelTree returns the tree of the levels of the named loggers
+// of the root logger, the tree is created on the first call.
+func (logger *Logger) levelTree() *levelTree {
+	root := logger.base()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if root.tree == nil {
+		root.tree = &levelTree{}
+	}
+
+	return root.tree
+}
+
+// The enabled returns true if the level is
+// enabled for the named logger, see SetLevels.
+func (logger *Logger) enabled(l level.Level) bool {
+	if logger.name == "" {
+		return true
+	}
+
+	levels := logger.tree.levels(logger.name)
+	return levels&l == l
+}
@@ -0,0 +1,151 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+)
+
+// TestLevelTree tests the lookup of the levels of the named loggers.
+func TestLevelTree(t *testing.T) {
+	debug := level.AtLeast(level.Debug)
+	info := level.AtLeast(level.Info)
+	errs := level.AtLeast(level.Error)
+
+	tree := &levelTree{}
+	tree.set("app", info)
+	tree.set("app.db.*", debug)
+	tree.set("app.http", errs)
+	tree.set("*.cache", errs)
+
+	tests := []struct {
+		name     string
+		expected level.Level
+	}{
+		{"other", level.Default},
+		{"app", info},
+		{"app.db", info}, // the pattern matches the children only
+		{"app.db.pool", debug},
+		{"app.db.pool.conn", debug},
+		{"app.http", errs},
+		{"app.http.router", errs}, // inherited from the parent
+		{"app.worker", info},
+		{"app.cache", errs},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tree.levels(test.name); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	// The rule that is set again becomes the last one and
+	// the cached levels are updated.
+	tree.set("app.db.*", errs)
+	if got := tree.levels("app.db.pool"); got != errs {
+		t.Errorf("expected %v, got %v", errs, got)
+	}
+
+	if !tree.reset("app.db.*") || tree.reset("app.db.*") {
+		t.Error("incorrect reset of the rule")
+	}
+
+	if got := tree.levels("app.db.pool"); got != info {
+		t.Errorf("expected %v, got %v", info, got)
+	}
+}
+
+// TestNamed tests the named loggers.
+func TestNamed(t *testing.T) {
+	root := New()
+	app := root.Named("app")
+	db := app.Named("db")
+	pool := db.Named("pool")
+
+	if app.Name() != "app" || db.Name() != "app.db" ||
+		pool.Name() != "app.db.pool" || root.Name() != "" {
+		t.Fatalf("incorrect names: %q %q %q", app.Name(), db.Name(),
+			pool.Name())
+	}
+
+	// The outputs are shared with the root, including the
+	// outputs that are set after the child is created.
+	buf := &bytes.Buffer{}
+	if err := pool.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if o := root.Outputs(); len(o) != 1 || o[0].Name != "test" {
+		t.Fatalf("the outputs are not shared: %v", o)
+	}
+
+	root.SetLevels("app", level.AtLeast(level.Info))
+	root.SetLevels("app.db.*", level.AtLeast(level.Debug))
+
+	root.Debugln("root debug")
+	app.Debugln("app debug")
+	app.Infoln("app info")
+	db.Debugln("db debug")
+	pool.Debugln("pool debug")
+
+	out := buf.String()
+	for _, s := range []string{"root debug", "app info", "pool debug"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("the record %q is not written: %s", s, out)
+		}
+	}
+
+	for _, s := range []string{"app debug", "db debug"} {
+		if strings.Contains(out, s) {
+			t.Errorf("the record %q is written: %s", s, out)
+		}
+	}
+
+	if err := root.SetLevels("[", level.Default); err == nil {
+		t.Error("expected error for the invalid pattern")
+	}
+}
+
+// TestNamedWith tests the children of the named logger.
+func TestNamedWith(t *testing.T) {
+	buf := &bytes.Buffer{}
+	root := New()
+	root.SetOutputs(Output{Name: "test", Writer: buf, Levels: level.Default})
+	root.SetLevels("app", level.AtLeast(level.Error))
+
+	app := root.Named("app")
+	child := app.With("id", 7)
+	if child.Name() != "app" || child.Levels() != app.Levels() {
+		t.Fatalf("incorrect child: %q %v", child.Name(), child.Levels())
+	}
+
+	child.Infoln("filtered")
+	child.Errorln("written")
+	if out := buf.String(); strings.Contains(out, "filtered") ||
+		!strings.Contains(out, "written id=7") {
+		t.Errorf("incorrect output: %s", out)
+	}
+
+	// The copy is independent.
+	cp := app.Copy()
+	cp.DeleteOutputs("test")
+	if cp.Name() != "" || len(cp.Outputs()) != 0 ||
+		len(root.Outputs()) != 1 {
+		t.Error("the copy is not independent")
+	}
+
+	// Closing the named logger closes the root.
+	app.Close()
+	root.Errorln("after close")
+	if root.DroppedAfterClose() != 1 || app.DroppedAfterClose() != 1 {
+		t.Errorf("the root is not closed")
+	}
+}
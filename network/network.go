@@ -0,0 +1,467 @@
+// Package network provides the network output for the logger: the
+// Writer sends the messages to the tcp://, udp:// or unix:// address,
+// frames them for the stream, reconnects with the exponential backoff
+// and buffers a bounded amount of data while the connection is down.
+//
+// Example usage:
+//
+//	w := &network.Writer{Address: "tcp://collector.local:5170"}
+//	defer w.Close()
+//
+//	logger.SetOutputs(log.Output{
+//	    Name:      "network",
+//	    Writer:    w,
+//	    TextStyle: trit.False, // JSON lines
+//	})
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Auto selects the framing by the network: the newline
+	// for the streams (TCP, unix) and no framing for the
+	// datagrams (UDP, unixgram).
+	Auto Framing = iota
+
+	// Newline terminates the message with a newline,
+	// if it doesn't end with the newline already.
+	Newline
+
+	// LengthPrefix prefixes the message with its length
+	// as 4-byte big-endian unsigned integer.
+	LengthPrefix
+
+	// OctetCounting prefixes the message with its length in
+	// decimal and a space, see RFC 6587, section 3.4.1.
+	OctetCounting
+
+	// NoFraming sends the message as is,
+	// it's used for the datagrams.
+	NoFraming
+)
+
+const (
+	// The defaultTimeout is the default timeout of
+	// the connection and of the write.
+	defaultTimeout = 5 * time.Second
+
+	// The defaultBackoff and defaultMaxBackoff are the default
+	// intervals between the attempts to connect.
+	defaultBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+
+	// The defaultBufferSize is the default size of the buffer
+	// of the messages that wait for the connection, in bytes.
+	defaultBufferSize = 1 << 20
+)
+
+// The currentTime returns the current time.
+// Redefined this function to be able to test the backoff.
+var currentTime = time.Now
+
+// ErrClosed is returned by the Write of the closed Writer.
+var ErrClosed = errors.New("network: writer is closed")
+
+// The errNotConnected is the reason of the dropped message
+// before the first attempt to connect.
+var errNotConnected = errors.New("not connected")
+
+// Framing is the type of the framing of the messages in the stream.
+type Framing int
+
+// Writer is the io.WriteCloser that sends each written message to the
+// network address. The connection is established in the background after
+// the first write, and is reestablished with the exponential backoff when
+// it fails: the write never waits for the connection. The Writer is safe
+// for concurrent use.
+//
+// While the connection is down, the messages are kept in the buffer and
+// are sent in order when it's reestablished: by the next write, Flush or
+// in the background. The Write returns an error only for the message that
+// doesn't fit into the buffer, it's dropped. So the logger reports it by
+// its error handler and passes the record to the fallback output. The
+// errors of the background attempts to connect are passed to the
+// ErrorHandler.
+type Writer struct {
+	// Address is the address of the receiver with the network as the
+	// scheme: "tcp://host:port", "udp://host:port", "unix:///path" or
+	// "unixgram:///path" (the tcp4, tcp6, udp4 and udp6 schemes are
+	// also supported), see ParseAddress.
+	Address string
+
+	// Framing is the framing of the messages, by default,
+	// it's selected by the network, see Auto.
+	Framing Framing
+
+	// Timeout is the timeout of the connection and of the write.
+	// The zero value is 5s.
+	Timeout time.Duration
+
+	// Backoff is the interval after the first failed attempt to
+	// connect, it's doubled after each failed attempt up to MaxBackoff.
+	// The writes during the backoff are buffered without the attempt.
+	// The zero values are 100ms and 30s.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// BufferSize is the maximum size of the framed messages buffered
+	// while the connection is down, in bytes. The zero value is 1MB,
+	// the negative value disables the buffering.
+	BufferSize int
+
+	// ErrorHandler handles the errors of the background attempts to
+	// connect, e.g. log.FromConfig reports them by the error handler of
+	// the logger. It's called by the background goroutine without the
+	// writer's lock held, so it can log to the logger that uses the
+	// writer. By default, the errors are ignored.
+	ErrorHandler func(err error)
+
+	network string // network of the parsed address
+	addr    string // address without the scheme
+	framing Framing
+
+	conn    net.Conn
+	pending [][]byte      // framed messages that wait for the connection
+	size    int           // total size of the pending messages
+	backoff time.Duration // current interval between the attempts
+	retryAt time.Time     // time of the next attempt to connect
+	lastErr error         // error of the last attempt to connect or write
+	timer   *time.Timer   // background attempt to connect and send
+	closed  bool
+
+	// The mu protects the state of the writer, the dmu serializes
+	// the deliveries of the pending messages: the connection is
+	// established with the dmu held, but without the mu held.
+	mu  sync.Mutex
+	dmu sync.Mutex
+}
+
+// ParseAddress splits the address of the Writer into the network and
+// the address of the net.Dial, e.g. "tcp://localhost:5170" into "tcp"
+// and "localhost:5170", "unix:///run/app.sock" into "unix" and
+// "/run/app.sock". It returns an error for an unsupported network
+// or an empty address.
+func ParseAddress(address string) (string, string, error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok || addr == "" {
+		return "", "", fmt.Errorf("network: invalid address %q", address)
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", "", fmt.Errorf("network: invalid address %q: %w",
+				address, err)
+		}
+	case "unix", "unixgram":
+	default:
+		return "", "", fmt.Errorf("network: unsupported network %q",
+			network)
+	}
+
+	return network, addr, nil
+}
+
+// Write sends p as one message if the connection is up, otherwise it
+// buffers the message until the connection is established in the
+// background. If the connection fails, the message is buffered and
+// the Writer reconnects in the background.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if w.network == "" {
+		network, addr, err := ParseAddress(w.Address)
+		if err != nil {
+			return 0, err
+		}
+
+		w.network, w.addr = network, addr
+		w.framing = w.Framing
+		if w.framing == Auto {
+			w.framing = autoFraming(network)
+		}
+	}
+
+	msg := frame(w.framing, p)
+	w.pending = append(w.pending, msg)
+	w.size += len(msg)
+
+	if w.conn != nil {
+		err := w.send()
+		if err == nil {
+			return len(p), nil
+		}
+
+		w.conn.Close()
+		w.conn, w.lastErr = nil, err
+	}
+
+	// The message is kept until the connection is reestablished.
+	w.schedule()
+	if w.size <= w.bufferSize() {
+		return len(p), nil
+	}
+
+	w.pending = w.pending[:len(w.pending)-1]
+	w.size -= len(msg)
+
+	err := w.lastErr
+	if err == nil {
+		err = errNotConnected
+	}
+
+	if w.bufferSize() == 0 {
+		return 0, fmt.Errorf("network: message dropped: %w", err)
+	}
+
+	return 0, fmt.Errorf("network: buffer is full, message dropped: %w", err)
+}
+
+// Flush sends the buffered messages, it connects without waiting
+// for the end of the backoff. It returns an error if the messages
+// cannot be sent, they are kept in the buffer.
+func (w *Writer) Flush() error {
+	w.dmu.Lock()
+	defer w.dmu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || len(w.pending) == 0 {
+		return nil
+	}
+
+	return w.deliver(true)
+}
+
+// Close sends the buffered messages and closes the connection, further
+// writes return ErrClosed. If the buffered messages cannot be sent, they
+// are lost and the error reports their size.
+func (w *Writer) Close() error {
+	w.dmu.Lock()
+	defer w.dmu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	var err error
+	if len(w.pending) != 0 {
+		if derr := w.deliver(true); derr != nil {
+			err = fmt.Errorf("network: %d bytes lost: %w", w.size, derr)
+		}
+
+		w.pending, w.size = nil, 0
+	}
+
+	if w.conn != nil {
+		err = errors.Join(err, w.conn.Close())
+		w.conn = nil
+	}
+
+	return err
+}
+
+// The deliver connects if the connection is down and sends the pending
+// messages in order, it reconnects once if the connection fails. If the
+// force is true, it connects without waiting for the end of the backoff.
+// If the messages cannot be sent, the background attempt is scheduled.
+//
+// The method must be called with the both locks of the writer held,
+// the mu is released while connecting.
+func (w *Writer) deliver(force bool) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(force); err != nil {
+				break
+			}
+		}
+
+		if err = w.send(); err == nil {
+			return nil
+		}
+
+		// The broken connection is reestablished immediately.
+		w.conn.Close()
+		w.conn, w.lastErr = nil, err
+	}
+
+	w.schedule()
+	return err
+}
+
+// The send writes the pending messages to the connection, the
+// written messages are removed from the buffer.
+//
+// The method must be called with the writer's lock held.
+func (w *Writer) send() error {
+	for len(w.pending) != 0 {
+		msg := w.pending[0]
+		w.conn.SetWriteDeadline(time.Now().Add(w.timeout()))
+		if _, err := w.conn.Write(msg); err != nil {
+			return err
+		}
+
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.size -= len(msg)
+	}
+
+	return nil
+}
+
+// The connect connects to the address, it fails without the attempt
+// during the backoff unless the force is true. The writes are buffered
+// while the connection is established.
+//
+// The method must be called with the both locks of the writer held,
+// the mu is released while connecting.
+func (w *Writer) connect(force bool) error {
+	if now := currentTime(); !force && now.Before(w.retryAt) {
+		return fmt.Errorf("network: reconnect in %s: %w",
+			w.retryAt.Sub(now).Round(time.Millisecond), w.lastErr)
+	}
+
+	w.mu.Unlock()
+	conn, err := net.DialTimeout(w.network, w.addr, w.timeout())
+	w.mu.Lock()
+
+	if err != nil {
+		first := w.firstBackoff()
+		limit := w.MaxBackoff
+		if limit <= 0 {
+			limit = defaultMaxBackoff
+		}
+
+		w.backoff = min(max(2*w.backoff, first), max(first, limit))
+		w.retryAt = currentTime().Add(w.backoff)
+		w.lastErr = err
+		return err
+	}
+
+	w.conn, w.backoff, w.retryAt, w.lastErr = conn, 0, time.Time{}, nil
+	return nil
+}
+
+// The schedule schedules the background attempt to connect and
+// to send the pending messages at the end of the backoff.
+//
+// The method must be called with the writer's lock held.
+func (w *Writer) schedule() {
+	if w.timer != nil || w.closed {
+		return
+	}
+
+	delay := max(w.retryAt.Sub(currentTime()), 0)
+	w.timer = time.AfterFunc(delay, w.retry)
+}
+
+// The retry connects and sends the pending messages in the background,
+// it's rescheduled until the connection is established and the messages
+// are sent, or the writer is closed.
+func (w *Writer) retry() {
+	w.dmu.Lock()
+	w.mu.Lock()
+
+	w.timer = nil
+	var err error
+	switch {
+	case w.closed, w.conn != nil && len(w.pending) == 0:
+	case w.conn == nil && currentTime().Before(w.retryAt):
+		w.schedule() // the attempt is made at the end of the backoff
+	default:
+		err = w.deliver(false)
+	}
+
+	w.mu.Unlock()
+	w.dmu.Unlock()
+
+	if err != nil && w.ErrorHandler != nil {
+		w.ErrorHandler(err)
+	}
+}
+
+// The timeout returns the timeout of the connection and of the write.
+func (w *Writer) timeout() time.Duration {
+	if w.Timeout <= 0 {
+		return defaultTimeout
+	}
+
+	return w.Timeout
+}
+
+// The firstBackoff returns the interval after
+// the first failed attempt to connect.
+func (w *Writer) firstBackoff() time.Duration {
+	if w.Backoff <= 0 {
+		return defaultBackoff
+	}
+
+	return w.Backoff
+}
+
+// The bufferSize returns the maximum size of the pending messages.
+func (w *Writer) bufferSize() int {
+	switch {
+	case w.BufferSize < 0:
+		return 0
+	case w.BufferSize == 0:
+		return defaultBufferSize
+	}
+
+	return w.BufferSize
+}
+
+// The frame returns the copy of the message framed for the stream.
+func frame(framing Framing, p []byte) []byte {
+	switch framing {
+	case Newline:
+		msg := append(make([]byte, 0, len(p)+1), p...)
+		if len(p) == 0 || p[len(p)-1] != '\n' {
+			msg = append(msg, '\n')
+		}
+
+		return msg
+	case LengthPrefix:
+		msg := binary.BigEndian.AppendUint32(
+			make([]byte, 0, len(p)+4), uint32(len(p)))
+		return append(msg, p...)
+	case OctetCounting:
+		msg := strconv.AppendInt(nil, int64(len(p)), 10)
+		return append(append(msg, ' '), p...)
+	}
+
+	return append([]byte(nil), p...)
+}
+
+// The autoFraming returns the framing of the messages for the network.
+func autoFraming(network string) Framing {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return NoFraming
+	}
+
+	return Newline
+}
@@ -0,0 +1,372 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The accept accepts the connection and reads n bytes from it.
+func accept(t *testing.T, ln net.Listener, n int) string {
+	t.Helper()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, n)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("%v: %q", err, buf)
+	}
+
+	return string(buf)
+}
+
+// TestParseAddress tests the ParseAddress function.
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		err     bool
+	}{
+		{"tcp://localhost:5170", "tcp", "localhost:5170", false},
+		{"udp6://[::1]:514", "udp6", "[::1]:514", false},
+		{"unix:///run/app.sock", "unix", "/run/app.sock", false},
+		{"unixgram://app.sock", "unixgram", "app.sock", false},
+		{"localhost:5170", "", "", true},
+		{"tcp://", "", "", true},
+		{"tcp://localhost", "", "", true},
+		{"http://localhost:80", "", "", true},
+	}
+
+	for _, test := range tests {
+		network, addr, err := ParseAddress(test.address)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error %v", test.address, err)
+		}
+
+		if network != test.network || addr != test.addr {
+			t.Errorf("%s: expected %s %s, got %s %s", test.address,
+				test.network, test.addr, network, addr)
+		}
+	}
+}
+
+// TestFrame tests the framing of the messages.
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		framing  Framing
+		msg      string
+		expected string
+	}{
+		{"Newline", Newline, "msg", "msg\n"},
+		{"Newline is not doubled", Newline, "msg\n", "msg\n"},
+		{"Newline of empty message", Newline, "", "\n"},
+		{"Length prefix", LengthPrefix, "msg\n", "\x00\x00\x00\x04msg\n"},
+		{"Octet counting", OctetCounting, "msg", "3 msg"},
+		{"No framing", NoFraming, "msg", "msg"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := []byte(test.msg)
+			got := frame(test.framing, p)
+			if string(got) != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+
+			// The message is copied, the caller can reuse p.
+			if len(p) != 0 && len(got) != 0 && &got[0] == &p[0] {
+				t.Error("the message isn't copied")
+			}
+		})
+	}
+}
+
+// TestWriterStream tests the Writer over the stream sockets.
+func TestWriterStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  string
+		framing  Framing
+		expected string
+	}{
+		{
+			name:     "TCP",
+			network:  "tcp",
+			expected: "first\nnext\n",
+		},
+		{
+			name:     "TCP with octet counting",
+			network:  "tcp",
+			framing:  OctetCounting,
+			expected: "6 first\n5 next\n",
+		},
+		{
+			name:     "Unix with length prefix",
+			network:  "unix",
+			framing:  LengthPrefix,
+			expected: "\x00\x00\x00\x06first\n\x00\x00\x00\x05next\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if test.network == "unix" {
+				addr = filepath.Join(t.TempDir(), "log.sock")
+			}
+
+			ln, err := net.Listen(test.network, addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+
+			w := &Writer{
+				Address: test.network + "://" + ln.Addr().String(),
+				Framing: test.framing,
+			}
+			defer w.Close()
+
+			for _, msg := range []string{"first\n", "next\n"} {
+				if _, err := w.Write([]byte(msg)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := accept(t, ln, len(test.expected)); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestWriterDatagram tests the Writer over the datagram sockets.
+func TestWriterDatagram(t *testing.T) {
+	for _, network := range []string{"udp", "unixgram"} {
+		t.Run(network, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if network == "unixgram" {
+				addr = filepath.Join(t.TempDir(), "log.sock")
+			}
+
+			conn, err := net.ListenPacket(network, addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			w := &Writer{Address: network + "://" + conn.LocalAddr().String()}
+			defer w.Close()
+
+			for _, msg := range []string{"first\n", "second\n"} {
+				if _, err := w.Write([]byte(msg)); err != nil {
+					t.Fatal(err)
+				}
+
+				buf := make([]byte, 1024)
+				conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := string(buf[:n]); got != msg {
+					t.Errorf("expected %q, got %q", msg, got)
+				}
+			}
+		})
+	}
+}
+
+// TestWriterBuffer tests the buffering while the connection is down.
+func TestWriterBuffer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	w := &Writer{
+		Address:    "unix://" + path,
+		Backoff:    time.Hour, // one background attempt
+		BufferSize: 10,
+	}
+	defer w.Close()
+
+	// The messages are buffered while nothing listens.
+	for _, msg := range []string{"one", "two"} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected the message to be buffered, got %v", err)
+		}
+	}
+
+	// The message that doesn't fit into the buffer is dropped.
+	_, err := w.Write([]byte("three"))
+	if err == nil || !strings.Contains(err.Error(), "buffer is full") {
+		t.Fatalf("expected the buffer error, got %v", err)
+	}
+
+	if err := w.Flush(); err == nil {
+		t.Fatal("expected the connection error")
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// The Flush connects without waiting for the end of the backoff.
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("four")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "one\ntwo\nfour\n"
+	if got := accept(t, ln, len(expected)); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestWriterBackground tests that the buffered messages
+// are sent in the background when the receiver is up.
+func TestWriterBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	w := &Writer{
+		Address:    "unix://" + path,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("buffered")); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if got := accept(t, ln, len("buffered\n")); got != "buffered\n" {
+		t.Errorf("expected %q, got %q", "buffered\n", got)
+	}
+}
+
+// TestWriterBackoff tests the backoff of the background attempts
+// to connect and the reports of their errors.
+func TestWriterBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // nothing listens on the address
+
+	var mu sync.Mutex
+	now := time.Now()
+	currentTime = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	defer func() { currentTime = time.Now }()
+
+	var reports atomic.Int64
+	w := &Writer{
+		Address:    "tcp://" + addr,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 80 * time.Millisecond,
+		ErrorHandler: func(err error) {
+			reports.Add(1)
+		},
+	}
+	defer w.Close()
+
+	// The message is buffered, the first attempt is made immediately.
+	if _, err := w.Write([]byte("msg")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interval is doubled after each failed attempt,
+	// no attempts are made during the backoff.
+	for i, backoff := range []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		80 * time.Millisecond,
+		80 * time.Millisecond,
+	} {
+		deadline := time.Now().Add(5 * time.Second)
+		for reports.Load() != int64(i+1) && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		w.mu.Lock()
+		current := w.backoff
+		w.mu.Unlock()
+		if n := reports.Load(); n != int64(i+1) || current != backoff {
+			t.Fatalf("expected %d attempts and backoff %s, "+
+				"got %d and %s", i+1, backoff, n, current)
+		}
+
+		mu.Lock()
+		now = now.Add(backoff)
+		mu.Unlock()
+	}
+}
+
+// TestWriterNoBuffer tests that the Writer without the buffer
+// drops the messages while the connection is down.
+func TestWriterNoBuffer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	w := &Writer{
+		Address:    "unix://" + path,
+		Backoff:    time.Hour,
+		BufferSize: -1,
+	}
+	defer w.Close()
+
+	_, err := w.Write([]byte("msg"))
+	if err == nil || !strings.Contains(err.Error(), "message dropped") {
+		t.Fatalf("expected the dropped message error, got %v", err)
+	}
+}
+
+// TestWriterClose tests the Close of the Writer.
+func TestWriterClose(t *testing.T) {
+	w := &Writer{
+		Address: "unix://" + filepath.Join(t.TempDir(), "log.sock"),
+		Backoff: time.Hour,
+	}
+
+	if _, err := w.Write([]byte("lost")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The buffered messages that cannot be sent are reported.
+	err := w.Close()
+	if err == nil || !strings.Contains(err.Error(), "5 bytes lost") {
+		t.Fatalf("expected the lost bytes error, got %v", err)
+	}
+
+	if _, err := w.Write([]byte("msg")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("the second Close failed: %v", err)
+	}
+}
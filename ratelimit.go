@@ -0,0 +1,233 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// The rateLimitReportInterval is the interval of the reports
+// of the records dropped by the rate limiter.
+const rateLimitReportInterval = time.Second
+
+// RateLimit is the hard limit of the records written to the output,
+// it's enforced by the token buckets: the records (or bytes) that
+// exceed the limit are dropped. The zero value means no limit.
+//
+// The number of the dropped records is reported to the output by
+// the Warn record "log: N records dropped by rate limiter on output X"
+// once per second while the records are dropped.
+type RateLimit struct {
+	// Records is the number of records per second,
+	// zero means no limit on the number of records.
+	Records float64
+
+	// Burst is the maximum number of records written at once,
+	// by default, it's the Records rounded up.
+	Burst int
+
+	// Bytes is the number of bytes of the formatted records per
+	// second, zero means no limit on the size of the records.
+	Bytes int
+
+	// BytesBurst is the maximum number of bytes written at once,
+	// by default, it's the Bytes.
+	BytesBurst int
+}
+
+// The tokenBucket is the token bucket of the rate limiter.
+type tokenBucket struct {
+	rate   float64 // tokens per second
+	burst  float64 // capacity of the bucket
+	tokens float64
+	last   time.Time
+}
+
+// The take takes n tokens from the bucket if it has them.
+func (b *tokenBucket) take(n float64, now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+
+	b.last = now
+	if b.tokens < n {
+		return false
+	}
+
+	b.tokens -= n
+	return true
+}
+
+// The rateLimiter enforces the RateLimit of the output.
+type rateLimiter struct {
+	limit   RateLimit
+	records tokenBucket
+	bytes   tokenBucket
+
+	dropped   atomic.Uint64 // total number of dropped records
+	pending   int           // number of dropped records to report
+	reporting bool          // the report is scheduled
+	mu        sync.Mutex
+}
+
+// The newRateLimiter creates the rate limiter for the limit.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	rl := &rateLimiter{limit: limit}
+	rl.records.rate = limit.Records
+	rl.records.burst = float64(limit.Burst)
+	if limit.Burst <= 0 {
+		rl.records.burst = math.Max(1, math.Ceil(limit.Records))
+	}
+
+	rl.bytes.rate = float64(limit.Bytes)
+	rl.bytes.burst = float64(limit.BytesBurst)
+	if limit.BytesBurst <= 0 {
+		rl.bytes.burst = float64(limit.Bytes)
+	}
+
+	return rl
+}
+
+// The allow takes the token for the record and returns true if the
+// record can be written. Otherwise, the record is counted as dropped,
+// and the second value is true if the report must be scheduled.
+func (rl *rateLimiter) allow(now time.Time) (bool, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.records.take(1, now) {
+		return true, false
+	}
+
+	return false, rl.drop()
+}
+
+// The allowBytes takes the tokens for the formatted record of the
+// size, see allow.
+func (rl *rateLimiter) allowBytes(size int, now time.Time) (bool, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.bytes.take(float64(size), now) {
+		return true, false
+	}
+
+	return false, rl.drop()
+}
+
+// The drop counts the dropped record and returns true if
+// the report must be scheduled.
+//
+// The method must be called with the limiter's lock held.
+func (rl *rateLimiter) drop() bool {
+	rl.dropped.Add(1)
+	rl.pending++
+	if rl.reporting {
+		return false
+	}
+
+	rl.reporting = true
+	return true
+}
+
+// The report returns the number of the dropped records
+// to report and resets it.
+func (rl *rateLimiter) report() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	n := rl.pending
+	rl.pending = 0
+	rl.reporting = false
+	return n
+}
+
+// RateLimited returns the number of records dropped
+// by the rate limiter of the output (see Output.RateLimit).
+func (o *Output) RateLimited() uint64 {
+	if o.limiter == nil {
+		return 0
+	}
+
+	return o.limiter.dropped.Load()
+}
+
+// The syncLimiter creates, replaces or removes the rate
+// limiter of the output according to its RateLimit.
+func (o *Output) syncLimiter() {
+	switch {
+	case o.RateLimit == (RateLimit{}):
+		o.limiter = nil
+	case o.limiter == nil || o.limiter.limit != o.RateLimit:
+		o.limiter = newRateLimiter(o.RateLimit)
+	}
+}
+
+// The rateLimited checks the rate limiter of the output for the
+// record: before formatting if the data is nil, or for the formatted
+// data. It returns true if the record must be dropped.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) rateLimited(
+	name string,
+	o *Output,
+	r *Record,
+	data []byte,
+) bool {
+	rl := o.limiter
+	if rl == nil || r.synthetic {
+		return false
+	}
+
+	var ok, report bool
+	if data == nil {
+		ok, report = rl.allow(time.Now())
+	} else {
+		ok, report = rl.allowBytes(len(data), time.Now())
+	}
+
+	if report {
+		time.AfterFunc(rateLimitReportInterval, func() {
+			logger.reportRateLimit(name, rl)
+		})
+	}
+
+	return !ok
+}
+
+// The reportRateLimit writes the number of the records dropped
+// by the rate limiter to the output.
+func (logger *Logger) reportRateLimit(name string, rl *rateLimiter) {
+	n := rl.report()
+	if n == 0 {
+		return
+	}
+
+	defer logger.handleErrors()
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	// The output can be edited or deleted in the meantime,
+	// the report is written if it still has the limiter.
+	o, ok := logger.outputs[name]
+	if !ok || o.limiter != rl || logger.closed {
+		return
+	}
+
+	msg := fmt.Sprintf("log: %d records dropped by rate limiter on output %s",
+		n, name)
+	r := newRecord(logger.prefix, level.Warn, &stackFrame{}, nil,
+		formatPrintln, []any{msg})
+	r.synthetic = true
+	logger.write(name, o, r)
+}
@@ -0,0 +1,152 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// TestTokenBucket tests the take method of the tokenBucket.
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		limit    RateLimit
+		elapsed  time.Duration // time between the records
+		n        int           // number of records
+		expected int           // number of passed records
+	}{
+		{
+			name:     "Burst by default",
+			limit:    RateLimit{Records: 3},
+			n:        10,
+			expected: 3,
+		},
+		{
+			name:     "Custom burst",
+			limit:    RateLimit{Records: 3, Burst: 5},
+			n:        10,
+			expected: 5,
+		},
+		{
+			name:     "Fractional rate",
+			limit:    RateLimit{Records: 0.5},
+			n:        10,
+			expected: 1,
+		},
+		{
+			name:     "Refill",
+			limit:    RateLimit{Records: 10, Burst: 1},
+			elapsed:  50 * time.Millisecond,
+			n:        10,
+			expected: 5, // one token per 100ms
+		},
+		{
+			name:     "Bytes only",
+			limit:    RateLimit{Bytes: 10},
+			n:        10,
+			expected: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl := newRateLimiter(test.limit)
+			passed := 0
+			for i := 0; i < test.n; i++ {
+				at := now.Add(time.Duration(i) * test.elapsed)
+				if ok, _ := rl.allow(at); ok {
+					passed++
+				}
+			}
+
+			if passed != test.expected {
+				t.Errorf("expected %d passed records, got %d",
+					test.expected, passed)
+			}
+
+			if d := rl.dropped.Load(); d != uint64(test.n-passed) {
+				t.Errorf("expected %d dropped records, got %d",
+					test.n-passed, d)
+			}
+		})
+	}
+}
+
+// TestOutputRateLimit tests the rate limit of the output.
+func TestOutputRateLimit(t *testing.T) {
+	limited, other := &syncBuffer{}, &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(
+		Output{
+			Name:      "limited",
+			Writer:    limited,
+			Levels:    level.Default,
+			RateLimit: RateLimit{Records: 1, Burst: 3},
+		},
+		Output{
+			Name:   "other",
+			Writer: other,
+			Levels: level.Default,
+		},
+	)
+
+	for i := 0; i < 100; i++ {
+		logger.Infoln("record", i)
+	}
+
+	// Other outputs are not affected.
+	if n := strings.Count(other.String(), "record"); n != 100 {
+		t.Errorf("expected 100 records in the other output, got %d", n)
+	}
+
+	if n := strings.Count(limited.String(), "record"); n != 3 {
+		t.Errorf("expected 3 records in the limited output, got %d", n)
+	}
+
+	o := logger.Outputs("limited")
+	if n := o[0].RateLimited(); n != 97 {
+		t.Errorf("expected 97 dropped records, got %d", n)
+	}
+
+	// The report is written to the limited output only.
+	report := "log: 97 records dropped by rate limiter on output limited"
+	waitFor(t, func() bool {
+		return strings.Contains(limited.String(), report)
+	})
+
+	if strings.Contains(other.String(), "dropped by rate limiter") {
+		t.Errorf("the report is written to the other output: %s", other)
+	}
+}
+
+// TestOutputRateLimitBytes tests the rate limit of the output in bytes.
+func TestOutputRateLimitBytes(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:      "limited",
+		Writer:    buf,
+		Levels:    level.Default,
+		RateLimit: RateLimit{Bytes: 1, BytesBurst: 1024},
+	})
+
+	msg := strings.Repeat("x", 100)
+	for i := 0; i < 100; i++ {
+		logger.Infoln(msg)
+	}
+
+	n := strings.Count(buf.String(), msg)
+	if n == 0 || n > 10 {
+		t.Errorf("expected up to 10 records, got %d", n)
+	}
+
+	// The empty limit doesn't change the output in the edit mode.
+	logger.EditOutputs(Output{Name: "limited", RateLimit: RateLimit{}})
+	o := logger.Outputs("limited")
+	if o[0].RateLimited() == 0 {
+		t.Error("the limiter is reset by the empty edit")
+	}
+}
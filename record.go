@@ -0,0 +1,195 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// Record is the log-message passed to the hooks and the formatters:
+// the data of the event before it is formatted by the outputs. The
+// hooks can change the data, e.g. add fields or replace the message.
+type Record struct {
+	Prefix  string      // prefix of the logger
+	Level   level.Level // level of the message
+	Time    time.Time   // time of the event
+	Message string      // user's message without the trailing newline
+	Fields  []Field     // fields of the message
+
+	FilePath    string  // path to the file where the message was logged
+	LineNumber  int     // line number
+	FuncName    string  // function name
+	FuncAddress uintptr // address of the function
+	PC          uintptr // program counter, can be 0
+
+	// The format is the format type of the message: print, println
+	// or printf-like, it determines whether the rendered message
+	// ends with a newline character.
+	format string
+
+	// The newline is true if the printf-like message ended with
+	// a newline character, it's trimmed from the Message.
+	newline bool
+
+	// The template is the format string of the printf-like message,
+	// it's used as the key of the sampling.
+	template string
+
+	// The function is the full name of the function with the
+	// import path of its package, it's used by the filters.
+	function string
+
+	// The failed is the list of the outputs that failed to
+	// write the record, see Output.Fallback.
+	failed []string
+
+	// The ctx is the context of the message, it's passed
+	// to the slog outputs, it's nil for the most messages.
+	ctx context.Context
+
+	// The contextKeys is the list of the keys of the fields extracted
+	// from the context, the text style renders them in the header.
+	contextKeys []string
+
+	// The arguments of the user's message and the lazy flag: the
+	// message is formatted by the render when the record is accepted
+	// by a hook or an output, see render.
+	arguments []any
+	lazy      bool
+
+	// The synthetic is true for the records generated by the logger
+	// itself, e.g. the reports of the sampling, they are not limited.
+	synthetic bool
+}
+
+// AddFields adds the key/value pairs to the fields of the record,
+// if the key is already bound, its value is replaced. The keys are
+// handled as in Logger.With.
+func (r *Record) AddFields(kv ...any) {
+	r.Fields = mergeFields(r.Fields, makeFields(kv...))
+}
+
+// The newRecord creates the record of the message. The f and a are
+// the format and the arguments of the user's message, the message
+// isn't formatted until the record is accepted, see render.
+func newRecord(
+	p string,
+	l level.Level,
+	sf *stackFrame,
+	fields []Field,
+	f string,
+	a []any,
+) *Record {
+	r := &Record{
+		Prefix:      p,
+		Level:       l,
+		Time:        time.Now(),
+		Fields:      fields,
+		FilePath:    sf.FilePath,
+		LineNumber:  sf.FileLine,
+		FuncName:    sf.FuncName,
+		FuncAddress: sf.FuncAddress,
+		PC:          sf.PC,
+		function:    sf.Function,
+		format:      "%s",
+		arguments:   a,
+		lazy:        true,
+	}
+
+	switch f {
+	case "", formatPrint:
+		r.format = formatPrint
+	case formatPrintln:
+		r.format = formatPrintln
+	default:
+		r.template = f
+	}
+
+	return r
+}
+
+// The render formats the user's message of the record once, the
+// message of the rejected record is never formatted. The record
+// must be rendered before it's passed to the hooks or the outputs.
+func (r *Record) render() {
+	if !r.lazy {
+		return
+	}
+
+	switch r.format {
+	case formatPrintln:
+		r.Message = formatMessage(formatPrintln, r.arguments)
+		r.Message = r.Message[:len(r.Message)-1] // trailing newline
+	case formatPrint:
+		r.Message = formatMessage(formatPrint, r.arguments)
+	default:
+		r.Message = formatMessage(r.template, r.arguments)
+		r.Message, r.newline = strings.CutSuffix(r.Message, "\n")
+	}
+
+	r.arguments, r.lazy = nil, false
+}
+
+// The args returns the format and the arguments that render the message
+// of the record as it was logged: with or without the trailing newline.
+func (r *Record) args() (string, []any) {
+	if r.newline {
+		return r.format + "\n", []any{r.Message}
+	}
+
+	return r.format, []any{r.Message}
+}
+
+// The prefix returns the prefix of the record if
+// the output shows it, otherwise an empty string.
+func (r *Record) prefix(o *Output) string {
+	if !o.WithPrefix.IsTrue() {
+		return ""
+	}
+
+	return r.Prefix
+}
+
+// The splitFields returns the fields of the context (see contextKeys)
+// and the other fields of the record.
+func (r *Record) splitFields() ([]Field, []Field) {
+	if len(r.contextKeys) == 0 {
+		return nil, r.Fields
+	}
+
+	var header, rest []Field
+	for _, f := range r.Fields {
+		if slices.Contains(r.contextKeys, f.Key) {
+			header = append(header, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+
+	return header, rest
+}
+
+// The context returns the context of the record,
+// or the background context if it has none.
+func (r *Record) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
+
+// The stackFrame returns the stack frame of the record.
+func (r *Record) stackFrame() *stackFrame {
+	return &stackFrame{
+		FileLine:    r.LineNumber,
+		FuncName:    r.FuncName,
+		FuncAddress: r.FuncAddress,
+		FilePath:    r.FilePath,
+		PC:          r.PC,
+		Function:    r.function,
+	}
+}
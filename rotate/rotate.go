@@ -0,0 +1,541 @@
+// Package rotate provides a rotating file writer for the log outputs.
+//
+// The File rolls the log file over when it reaches the maximum size or
+// when the rotation interval (aligned to the wall clock) passes, keeps
+// a limited number of backups, optionally compresses them with gzip,
+// and can reopen the file by a signal for compatibility with logrotate.
+//
+// Example usage:
+//
+//	f := &rotate.File{
+//	    Path:       "/var/log/app.log",
+//	    MaxSize:    100 << 20, // 100 MB
+//	    Interval:   rotate.Daily,
+//	    MaxBackups: 7,
+//	    Compress:   true,
+//	}
+//	defer f.Close()
+//
+//	logger.SetOutputs(log.Output{
+//	    Name:   "file",
+//	    Writer: f,
+//	})
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// None disables rotation by time.
+	None Interval = iota
+
+	// Hourly rotates the file at the beginning of each hour.
+	Hourly
+
+	// Daily rotates the file at midnight (local time).
+	Daily
+)
+
+const (
+	// The backupTimeFormat is the format of the timestamp
+	// that is added to the name of the backup file.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+
+	// The compressSuffix is the suffix of the compressed backup file.
+	compressSuffix = ".gz"
+
+	// The defaultPerm is the default permissions of the log file.
+	defaultPerm = 0o644
+)
+
+// The currentTime returns the current time.
+// Redefined this function to be able to test the rotation.
+var currentTime = time.Now
+
+// Interval is the type of the rotation interval.
+type Interval int
+
+// File is the io.WriteCloser that writes to the file and rotates it.
+// The file is opened on the first write. The File is safe for
+// concurrent use.
+//
+// The backup files are stored in the same directory as the file, with
+// the rotation time added to the name, e.g. for app.log the backup is
+// app-2006-01-02T15-04-05.000.log (and app-...log.gz if compressed).
+type File struct {
+	// Path is the path to the log file.
+	//
+	// Mandatory parameter, cannot be empty.
+	Path string
+
+	// MaxSize is the maximum size of the file in bytes.
+	// The file is rotated before writing if the write would exceed it.
+	// The zero value disables rotation by size.
+	MaxSize int64
+
+	// Interval is the rotation interval: None, Hourly or Daily.
+	Interval Interval
+
+	// MaxBackups is the maximum number of the backup files to keep.
+	// The zero value keeps all backup files (subject to MaxAge).
+	MaxBackups int
+
+	// MaxAge is the maximum time to keep the backup files.
+	// The zero value keeps the backup files regardless of their age.
+	MaxAge time.Duration
+
+	// Compress is the flag that determines whether to compress
+	// the backup files with gzip.
+	Compress bool
+
+	// Perm is the permissions of the new log file.
+	// The zero value is 0644.
+	Perm os.FileMode
+
+	file   *os.File  // current file
+	size   int64     // size of the current file
+	next   time.Time // time of the next rotation by interval
+	closed bool      // the file was closed by Close
+
+	millCh   chan struct{}  // signals to compress and clean backups
+	millDone chan struct{}  // closed when the mill goroutine stops
+	stopSig  chan os.Signal // signals to reopen the file
+
+	mu sync.Mutex
+}
+
+// Write writes p to the file. It rotates the file if the write would
+// exceed MaxSize or if the rotation interval has passed.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync commits the current contents of the file to stable storage.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Sync()
+}
+
+// Close closes the file and stops the background goroutines.
+// Further writes return os.ErrClosed.
+func (f *File) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+
+	f.closed = true
+	err := f.close()
+
+	if f.stopSig != nil {
+		signal.Stop(f.stopSig)
+		close(f.stopSig)
+		f.stopSig = nil
+	}
+
+	millCh, millDone := f.millCh, f.millDone
+	f.millCh = nil
+	f.mu.Unlock()
+
+	// Wait for the compression of the last backup.
+	if millCh != nil {
+		close(millCh)
+		<-millDone
+	}
+
+	return err
+}
+
+// Rotate closes the current file, renames it to the backup file
+// and opens a new file at the original path.
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes and reopens the file at the original path. It is used
+// when the file was moved by an external tool, e.g. logrotate, so that
+// the writing continues to the new file.
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	if err := f.close(); err != nil {
+		return err
+	}
+
+	return f.open()
+}
+
+// ReopenOnSignal reopens the file each time the process receives one
+// of the specified signals. If no signals are specified, SIGHUP is used.
+// Signal handling stops when the file is closed.
+//
+// Example usage:
+//
+//	f := &rotate.File{Path: "/var/log/app.log"}
+//	f.ReopenOnSignal() // for logrotate with postrotate kill -HUP
+func (f *File) ReopenOnSignal(sig ...os.Signal) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed || f.stopSig != nil {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	f.stopSig = ch
+
+	go func() {
+		for range ch {
+			f.Reopen()
+		}
+	}()
+}
+
+// The open opens the file at the path for appending, creating it if
+// necessary. If the file was last modified in the previous rotation
+// interval, it is rotated at once.
+//
+// The method must be called with the lock held.
+func (f *File) open() error {
+	if f.Path == "" {
+		return errors.New("the path of the file is empty")
+	}
+
+	perm := f.Perm
+	if perm == 0 {
+		perm = defaultPerm
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	file, err := os.OpenFile(f.Path, flag, perm)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	now := currentTime()
+	f.file = file
+	f.size = info.Size()
+	f.next = nextRotation(f.Interval, now)
+
+	// The file was written in the previous interval.
+	if f.Interval != None && f.size > 0 &&
+		!nextRotation(f.Interval, info.ModTime()).After(now) {
+		return f.rotate()
+	}
+
+	return nil
+}
+
+// The close closes the current file.
+//
+// The method must be called with the lock held.
+func (f *File) close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	f.size = 0
+	return err
+}
+
+// The needsRotation returns true if the file must be rotated
+// before writing n bytes.
+//
+// The method must be called with the lock held.
+func (f *File) needsRotation(n int64) bool {
+	if f.MaxSize > 0 && f.size > 0 && f.size+n > f.MaxSize {
+		return true
+	}
+
+	return f.Interval != None && !currentTime().Before(f.next)
+}
+
+// The rotate renames the current file to the backup file
+// and opens a new file.
+//
+// The method must be called with the lock held.
+func (f *File) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+
+	// Several rotations can happen within a millisecond,
+	// the backup must not overwrite the previous one.
+	t := currentTime()
+	name := backupName(f.Path, t)
+	for exists(name) || exists(name+compressSuffix) {
+		t = t.Add(time.Millisecond)
+		name = backupName(f.Path, t)
+	}
+
+	if err := os.Rename(f.Path, name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	perm := f.Perm
+	if perm == 0 {
+		perm = defaultPerm
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	file, err := os.OpenFile(f.Path, flag, perm)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	f.size = 0
+	f.next = nextRotation(f.Interval, currentTime())
+	f.mill()
+	return nil
+}
+
+// The mill starts compression and removal of old backups
+// in the background goroutine.
+//
+// The method must be called with the lock held.
+func (f *File) mill() {
+	if !f.Compress && f.MaxBackups <= 0 && f.MaxAge <= 0 {
+		return
+	}
+
+	if f.millCh == nil {
+		f.millCh = make(chan struct{}, 1)
+		f.millDone = make(chan struct{})
+		go f.millRun(f.millCh, f.millDone)
+	}
+
+	select {
+	case f.millCh <- struct{}{}:
+	default: // the mill is already scheduled
+	}
+}
+
+// The millRun compresses and removes backups on each signal of the ch.
+func (f *File) millRun(ch chan struct{}, done chan struct{}) {
+	defer close(done)
+	for range ch {
+		f.millOnce()
+	}
+}
+
+// The millOnce compresses the backups (if Compress is set) and removes
+// the backups exceeding MaxBackups or older than MaxAge.
+func (f *File) millOnce() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	now := currentTime()
+	for i, b := range backups {
+		switch {
+		case f.MaxBackups > 0 && i >= f.MaxBackups,
+			f.MaxAge > 0 && now.Sub(b.time) > f.MaxAge:
+			if err := os.Remove(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		case f.Compress && !strings.HasSuffix(b.path, compressSuffix):
+			if err := compress(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// The backup is the information about the backup file.
+type backup struct {
+	path string
+	time time.Time
+}
+
+// The backups returns the list of the backup files of the file,
+// sorted from newest to oldest.
+func (f *File) backups() ([]backup, error) {
+	dir := filepath.Dir(f.Path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := splitName(f.Path)
+	result := make([]backup, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix+"-") ||
+			!strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		ts := name[len(prefix)+1 : len(name)-len(ext)]
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, backup{
+			path: filepath.Join(dir, e.Name()),
+			time: t,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].time.After(result[j].time)
+	})
+
+	return result, nil
+}
+
+// The compress compresses the file with gzip and removes the source.
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(
+		path+compressSuffix,
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		info.Mode(),
+	)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + compressSuffix)
+		return err
+	}
+
+	if err := errors.Join(gz.Close(), dst.Close()); err != nil {
+		os.Remove(path + compressSuffix)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
+
+// The splitName splits the base name of the file into the name without
+// extension and the extension, e.g. for /var/log/app.log returns "app"
+// and ".log".
+func splitName(path string) (string, string) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext), ext
+}
+
+// The backupName returns the path of the backup file for the time.
+func backupName(path string, t time.Time) string {
+	name, ext := splitName(path)
+	return filepath.Join(
+		filepath.Dir(path),
+		fmt.Sprintf("%s-%s%s", name, t.Format(backupTimeFormat), ext),
+	)
+}
+
+// The exists returns true if the file exists.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// The nextRotation returns the time of the next rotation after t
+// aligned to the wall clock, or the zero time if the interval is None.
+func nextRotation(i Interval, t time.Time) time.Time {
+	switch i {
+	case Hourly:
+		y, m, d := t.Date()
+		return time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+	case Daily:
+		y, m, d := t.Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,287 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The fakeClock is the current time for the test, it's safe
+// to use from the mill goroutine.
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+// Add moves the clock forward by d.
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Now returns the current time of the clock.
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// The setTime sets the current time for the test.
+func setTime(t *testing.T, now time.Time) *fakeClock {
+	c := &fakeClock{now: now}
+	currentTime = c.Now
+	t.Cleanup(func() { currentTime = time.Now })
+	return c
+}
+
+// The readBackups returns the names of the backup files in the dir.
+func readBackups(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result []string
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			result = append(result, e.Name())
+		}
+	}
+
+	return result
+}
+
+// TestWrite tests the Write method without rotation.
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	f := &File{Path: path}
+
+	if _, err := f.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello\n" {
+		t.Errorf("expected `hello`, got `%s`", data)
+	}
+
+	if _, err := f.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("expected os.ErrClosed, got %v", err)
+	}
+
+	if _, err := (&File{}).Write([]byte("no path")); err == nil {
+		t.Error("expected an error for the empty path")
+	}
+}
+
+// TestRotateBySize tests rotation by MaxSize and MaxBackups.
+func TestRotateBySize(t *testing.T) {
+	now := setTime(t, time.Date(2023, 6, 26, 11, 0, 0, 0, time.Local))
+
+	dir := t.TempDir()
+	f := &File{
+		Path:       filepath.Join(dir, "app.log"),
+		MaxSize:    10,
+		MaxBackups: 2,
+	}
+
+	for i := 0; i < 4; i++ {
+		now.Add(time.Second)
+		if _, err := f.Write([]byte("123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := readBackups(t, dir)
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", backups)
+	}
+
+	// The newest backups are kept.
+	want := "app-2023-06-26T11-00-04.000.log"
+	if backups[1] != want {
+		t.Errorf("expected %s, got %v", want, backups)
+	}
+}
+
+// TestRotateByInterval tests rotation by Interval.
+func TestRotateByInterval(t *testing.T) {
+	now := setTime(t, time.Date(2023, 6, 26, 11, 59, 0, 0, time.Local))
+
+	dir := t.TempDir()
+	f := &File{Path: filepath.Join(dir, "app.log"), Interval: Hourly}
+	defer f.Close()
+
+	f.Write([]byte("first\n"))
+	f.Write([]byte("second\n"))
+	if backups := readBackups(t, dir); len(backups) != 0 {
+		t.Fatalf("unexpected rotation: %v", backups)
+	}
+
+	now.Add(time.Minute)
+	f.Write([]byte("third\n"))
+
+	backups := readBackups(t, dir)
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup, got %v", backups)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, backups[0]))
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("incorrect backup: %s", data)
+	}
+}
+
+// TestCompress tests compression of the backups.
+func TestCompress(t *testing.T) {
+	dir := t.TempDir()
+	f := &File{Path: filepath.Join(dir, "app.log"), Compress: true}
+
+	f.Write([]byte("compressed\n"))
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := readBackups(t, dir)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".log.gz") {
+		t.Fatalf("expected compressed backup, got %v", backups)
+	}
+
+	file, err := os.Open(filepath.Join(dir, backups[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := io.ReadAll(gz)
+	if string(data) != "compressed\n" {
+		t.Errorf("incorrect backup: %s", data)
+	}
+}
+
+// TestMaxAge tests removal of old backups.
+func TestMaxAge(t *testing.T) {
+	now := time.Now()
+	setTime(t, now)
+
+	dir := t.TempDir()
+	old := backupName(filepath.Join(dir, "app.log"), now.Add(-48*time.Hour))
+	if err := os.WriteFile(old, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &File{Path: filepath.Join(dir, "app.log"), MaxAge: 24 * time.Hour}
+	f.Write([]byte("new\n"))
+	f.Rotate()
+	f.Close()
+
+	backups := readBackups(t, dir)
+	if len(backups) != 1 || filepath.Join(dir, backups[0]) == old {
+		t.Errorf("the old backup was not removed: %v", backups)
+	}
+}
+
+// TestReopen tests the Reopen method.
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f := &File{Path: path}
+	defer f.Close()
+
+	f.Write([]byte("before\n"))
+
+	// Move the file as logrotate does.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Write([]byte("after\n"))
+	data, _ := os.ReadFile(path)
+	if string(data) != "after\n" {
+		t.Errorf("expected `after`, got `%s`", data)
+	}
+}
+
+// TestConcurrentWrite tests the concurrent use of the File.
+func TestConcurrentWrite(t *testing.T) {
+	dir := t.TempDir()
+	f := &File{Path: filepath.Join(dir, "app.log"), MaxSize: 100}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				f.Write([]byte("0123456789\n"))
+			}
+		}()
+	}
+	wg.Wait()
+	f.Close()
+
+	total := 0
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		data, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		total += len(data)
+	}
+
+	if total != 100*11 {
+		t.Errorf("expected %d bytes, got %d", 100*11, total)
+	}
+}
+
+// TestNextRotation tests nextRotation function.
+func TestNextRotation(t *testing.T) {
+	now := time.Date(2023, 6, 26, 11, 42, 8, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		interval Interval
+		want     time.Time
+	}{
+		{"None", None, time.Time{}},
+		{"Hourly", Hourly, time.Date(2023, 6, 26, 12, 0, 0, 0, time.UTC)},
+		{"Daily", Daily, time.Date(2023, 6, 27, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRotation(tt.interval, now); !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
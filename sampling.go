@@ -0,0 +1,239 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// The samplingInterval is the default interval of the sampler.
+const samplingInterval = time.Second
+
+// Sampler limits the number of repetitive records: during each
+// interval it passes the first records of each kind, then every
+// Mth record, and suppresses the others. The number of suppressed
+// records is reported when the interval ends.
+//
+// The kind of the record is its level and the format string of the
+// printf-like methods (Infof, Debugf etc.), so the messages are not
+// formatted to be compared. For the print-like methods (Info, Debugln
+// etc.) that have no format string, the kind is the level and the
+// place in the code where the message is logged.
+//
+// The sampler can be set for the logger (see Logger.SetSampler) or
+// for the output (see Output.Sampler).
+type Sampler struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+
+	counters map[samplerKey]*samplerCounter
+	sweep    time.Time // time of the last eviction of the counters
+	mu       sync.Mutex
+}
+
+// The samplerKey is the kind of the sampled records.
+type samplerKey struct {
+	level  level.Level
+	format string
+	pc     uintptr
+}
+
+// The samplerCounter is the counter of the records of the same kind.
+type samplerCounter struct {
+	start      time.Time // start of the current interval
+	count      int       // number of records in the current interval
+	suppressed int       // number of suppressed records to report
+}
+
+// NewSampler returns a sampler that passes the first records of each
+// kind during the interval, then every thereafter-th record. If the
+// thereafter is not positive, all the records after the first ones are
+// suppressed. If the interval is not positive, it's one second.
+//
+// Example usage:
+//
+//	// Pass 10 records of each kind per second, then every 100th.
+//	logger.SetSampler(log.NewSampler(10, 100, time.Second))
+func NewSampler(first, thereafter int, interval time.Duration) *Sampler {
+	if interval <= 0 {
+		interval = samplingInterval
+	}
+
+	return &Sampler{
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		counters:   make(map[samplerKey]*samplerCounter),
+	}
+}
+
+// The sample counts the record of the kind and returns true if it
+// must be passed. If the record is the first suppressed record of the
+// interval, it also returns the time until the end of the interval,
+// when the suppressed records must be reported (see suppressed).
+func (s *Sampler) sample(key samplerKey, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The counters of the ended intervals are evicted once per
+	// interval, so the map doesn't grow with the number of kinds.
+	if now.Sub(s.sweep) >= s.interval {
+		s.evict(now)
+	}
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &samplerCounter{start: now}
+		s.counters[key] = c
+	} else if now.Sub(c.start) >= s.interval {
+		c.start, c.count = now, 0
+	}
+
+	c.count++
+	if c.count <= s.first ||
+		(s.thereafter > 0 && (c.count-s.first)%s.thereafter == 0) {
+		return true, 0
+	}
+
+	c.suppressed++
+	if c.suppressed == 1 {
+		return false, s.interval - now.Sub(c.start)
+	}
+
+	return false, 0
+}
+
+// The evict deletes the counters whose intervals ended and whose
+// suppressed records were reported.
+//
+// The method must be called with the sampler's lock held.
+func (s *Sampler) evict(now time.Time) {
+	s.sweep = now
+	for key, c := range s.counters {
+		if c.suppressed == 0 && now.Sub(c.start) >= s.interval {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// The suppressed returns the number of the suppressed
+// records of the kind and resets it.
+func (s *Sampler) suppressed(key samplerKey) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok {
+		return 0
+	}
+
+	n := c.suppressed
+	c.suppressed = 0
+	return n
+}
+
+// SetSampler sets the sampler of the default logger.
+func SetSampler(s *Sampler) {
+	self.SetSampler(s)
+}
+
+// SetSampler sets the sampler of the messages of the logger, see
+// Sampler. The messages are sampled before they are formatted, the
+// suppressed messages are not passed to the hooks and the outputs.
+// If s is nil, the messages are not sampled.
+//
+// Note: the copies of the logger (see Copy and With) share the sampler.
+func (logger *Logger) SetSampler(s *Sampler) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.sampler = s
+}
+
+// The sample returns true if the message must be logged according
+// to the sampler of the logger.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) sample(l level.Level, f string, sf *stackFrame) bool {
+	s := logger.sampler
+	key := samplingKey(l, f, sf.PC)
+	ok, report := s.sample(key, time.Now())
+	if report > 0 {
+		time.AfterFunc(report, func() {
+			n := s.suppressed(key)
+			if n == 0 {
+				return
+			}
+
+			defer logger.base().handleErrors()
+			logger.mu.RLock()
+			defer logger.mu.RUnlock()
+			logger.emit(nil, suppressedRecord(logger.prefix, key, sf, n))
+		})
+	}
+
+	return ok
+}
+
+// The sampleOutput returns true if the record must be written to
+// the output according to the sampler of the output.
+//
+// The method must be called with the logger's read lock held.
+func (logger *Logger) sampleOutput(name string, o *Output, r *Record) bool {
+	s := o.Sampler
+	key := samplingKey(r.Level, r.template, r.PC)
+	ok, report := s.sample(key, r.Time)
+	if report > 0 {
+		sf := r.stackFrame()
+		time.AfterFunc(report, func() {
+			n := s.suppressed(key)
+			if n == 0 {
+				return
+			}
+
+			// The output can be edited or deleted in the meantime,
+			// the report is written if it still has the sampler.
+			defer logger.handleErrors()
+			logger.mu.RLock()
+			defer logger.mu.RUnlock()
+			if o, ok := logger.outputs[name]; ok && o.Sampler == s {
+				logger.write(name, o, suppressedRecord(r.Prefix, key, sf, n))
+			}
+		})
+	}
+
+	return ok
+}
+
+// The samplingKey returns the kind of the record: the level and the
+// format string, or the level and the program counter of the caller
+// for the messages without the format string.
+func samplingKey(l level.Level, f string, pc uintptr) samplerKey {
+	switch f {
+	case "", formatPrint, formatPrintln:
+		return samplerKey{level: l, pc: pc}
+	}
+
+	return samplerKey{level: l, format: f}
+}
+
+// The suppressedRecord returns the record that reports
+// the number of suppressed records of the kind.
+func suppressedRecord(
+	p string,
+	key samplerKey,
+	sf *stackFrame,
+	n int,
+) *Record {
+	var fields []Field
+	if key.format != "" {
+		fields = []Field{{Key: "format", Value: key.format}}
+	}
+
+	msg := fmt.Sprintf("log: %d records suppressed by sampling", n)
+	r := newRecord(p, key.level, sf, fields, formatPrintln, []any{msg})
+	r.synthetic = true
+	return r
+}
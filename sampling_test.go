@@ -0,0 +1,165 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+// TestSampler tests the sample method of the Sampler.
+func TestSampler(t *testing.T) {
+	s := NewSampler(2, 3, time.Minute)
+	key := samplerKey{level: level.Debug, format: "cache miss %s"}
+	now := time.Now()
+
+	var passed []int
+	for i := 1; i <= 10; i++ {
+		ok, report := s.sample(key, now)
+		if ok {
+			passed = append(passed, i)
+		}
+
+		// The report is scheduled by the first suppressed record.
+		if (report > 0) != (i == 3) {
+			t.Errorf("unexpected report %s for the record %d", report, i)
+		}
+	}
+
+	// The first 2, then every 3rd.
+	if got := passed; len(got) != 4 ||
+		got[0] != 1 || got[1] != 2 || got[2] != 5 || got[3] != 8 {
+		t.Errorf("incorrect passed records: %v", got)
+	}
+
+	if n := s.suppressed(key); n != 6 {
+		t.Errorf("expected 6 suppressed records, got %d", n)
+	}
+
+	// The other kind and the next interval.
+	other := samplerKey{level: level.Info, format: "cache miss %s"}
+	if ok, _ := s.sample(other, now); !ok {
+		t.Error("the other kind was suppressed")
+	}
+
+	if ok, _ := s.sample(key, now.Add(time.Minute)); !ok {
+		t.Error("the next interval was suppressed")
+	}
+}
+
+// TestSamplerEvict tests the eviction of the counters of the sampler.
+func TestSamplerEvict(t *testing.T) {
+	s := NewSampler(1, 0, time.Minute)
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		s.sample(samplerKey{level: level.Info, pc: uintptr(i)}, now)
+	}
+
+	// The suppressed records of the kind are not reported yet.
+	pending := samplerKey{level: level.Debug, format: "cache miss %s"}
+	s.sample(pending, now)
+	s.sample(pending, now)
+
+	s.sample(samplerKey{level: level.Warn}, now.Add(time.Minute))
+	if len(s.counters) != 2 {
+		t.Fatalf("expected 2 counters, got %d", len(s.counters))
+	}
+
+	if n := s.suppressed(pending); n != 1 {
+		t.Errorf("expected 1 suppressed record, got %d", n)
+	}
+}
+
+// TestLoggerSampler tests the sampler of the logger.
+func TestLoggerSampler(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Default,
+	})
+	logger.SetSampler(NewSampler(2, 0, 50*time.Millisecond))
+
+	for i := 0; i < 100; i++ {
+		logger.Debugf("cache miss %d\n", i)
+		logger.Infoln("print", i) // the kind is the place in the code
+	}
+
+	out := buf.String()
+	if strings.Count(out, "cache miss") != 2 ||
+		strings.Count(out, "print") != 2 {
+		t.Fatalf("incorrect output: %s", out)
+	}
+
+	waitFor(t, func() bool {
+		return strings.Count(buf.String(), "98 records suppressed") == 2
+	})
+
+	format := `format="cache miss %d\n"`
+	if !strings.Contains(buf.String(), format) {
+		t.Errorf("incorrect report: %s", buf)
+	}
+}
+
+// TestOutputSampler tests the sampler of the output.
+func TestOutputSampler(t *testing.T) {
+	sampled, full := &syncBuffer{}, &bytes.Buffer{}
+	logger := New()
+	logger.SetOutputs(
+		Output{
+			Name:    "sampled",
+			Writer:  sampled,
+			Levels:  level.Default,
+			Sampler: NewSampler(1, 10, 50*time.Millisecond),
+		},
+		Output{Name: "full", Writer: full, Levels: level.Default},
+	)
+
+	for i := 0; i < 21; i++ {
+		logger.Warnf("slow request %d\n", i)
+	}
+
+	if n := strings.Count(full.String(), "slow request"); n != 21 {
+		t.Errorf("expected 21 records, got %d", n)
+	}
+
+	// The 1st, the 11th and the 21st.
+	if n := strings.Count(sampled.String(), "slow request"); n != 3 {
+		t.Errorf("expected 3 records, got %d: %s", n, sampled)
+	}
+
+	waitFor(t, func() bool {
+		return strings.Contains(sampled.String(), "18 records suppressed")
+	})
+
+	if strings.Contains(full.String(), "suppressed") {
+		t.Errorf("the report was written to the other output: %s", full)
+	}
+}
+
+// TestOutputSamplerReports tests that the reports of the sampler
+// of the logger are not suppressed by the sampler of the output.
+func TestOutputSamplerReports(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := New()
+	logger.SetOutputs(Output{
+		Name:    "test",
+		Writer:  buf,
+		Levels:  level.Default,
+		Sampler: NewSampler(1, 0, time.Hour),
+	})
+	logger.SetSampler(NewSampler(1, 0, 30*time.Millisecond))
+
+	for n := 1; n <= 2; n++ {
+		for i := 0; i < 3; i++ {
+			logger.Debugf("cache miss %d\n", i)
+		}
+
+		waitFor(t, func() bool {
+			return strings.Count(buf.String(), "2 records suppressed") == n
+		})
+	}
+}
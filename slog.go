@@ -0,0 +1,340 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/goloop/log/level"
+)
+
+const (
+	// SlogLevelTrace is the slog level that corresponds to level.Trace.
+	SlogLevelTrace = slog.LevelDebug - 4
+
+	// SlogLevelFatal is the slog level that corresponds to level.Fatal.
+	SlogLevelFatal = slog.LevelError + 4
+
+	// SlogLevelPanic is the slog level that corresponds to level.Panic.
+	SlogLevelPanic = slog.LevelError + 8
+
+	// The slogHandlerFunc is the prefix of the full names
+	// of the methods of the SlogHandler.
+	slogHandlerFunc = "github.com/goloop/log.(*SlogHandler)."
+)
+
+// SlogHandler is the slog.Handler that writes records through
+// the outputs of the Logger. Each output applies its own Levels,
+// Layouts, TextStyle etc. to the records.
+//
+// Note: the handler only writes records, i.e. records of the Fatal or
+// Panic levels neither terminate the program nor cause a panic.
+//
+// Example usage:
+//
+//	logger := log.New("APP")
+//	slog.SetDefault(slog.New(log.NewSlogHandler(logger)))
+//	slog.Info("request accepted", "user_id", 7)
+type SlogHandler struct {
+	logger *Logger
+	fields []Field // fields added by WithAttrs
+	groups []string
+}
+
+// NewSlogHandler returns a new slog.Handler that writes records
+// to the outputs of the logger.
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// Enabled reports whether at least one enabled output of
+// the logger accepts records of the level. The Filters of
+// the outputs are matched against the caller of the slog
+// method (the first function outside the log/slog package).
+func (h *SlogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	lvl := fromSlogLevel(l)
+	if !h.logger.enabled(lvl) {
+		return false
+	}
+
+	// The named logger uses the outputs of the root logger.
+	base := h.logger.base()
+	base.mu.RLock()
+	defer base.mu.RUnlock()
+
+	var caller *Record
+	for _, o := range base.outputs {
+		if !o.Enabled.IsTrue() {
+			continue
+		}
+
+		levels := o.Levels
+		if len(o.Filters) != 0 {
+			if caller == nil {
+				caller = slogCaller()
+			}
+
+			levels = o.levels(caller)
+		}
+
+		if has, err := levels.Contains(lvl); has && err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle writes the record to the outputs of the logger.
+// The attributes of the record and the fields extracted from
+// the ctx (see Logger.AddContextExtractors) are added to the
+// message as fields.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]Field, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, prefix, a)
+		return true
+	})
+
+	defer h.logger.base().handleErrors()
+	h.logger.mu.RLock()
+	defer h.logger.mu.RUnlock()
+
+	sf := getStackFrameByPC(r.PC)
+	lvl := fromSlogLevel(r.Level)
+	if !h.logger.enabled(lvl) {
+		return nil
+	}
+
+	if h.logger.sampler != nil && !h.logger.sample(lvl, "", sf) {
+		return nil
+	}
+
+	cf := makeFields(h.logger.contextFields(ctx)...)
+	fields = mergeFields(cf, fields)
+	fields = mergeFields(h.logger.fields, fields)
+	rec := newRecord(
+		h.logger.prefix,
+		lvl,
+		sf,
+		fields,
+		formatPrintln,
+		[]any{r.Message},
+	)
+	if !r.Time.IsZero() {
+		rec.Time = r.Time
+	}
+
+	rec.ctx, rec.contextKeys = ctx, fieldKeys(cf)
+	h.logger.emit(nil, rec)
+
+	return nil
+}
+
+// WithAttrs returns a new handler whose records contain
+// the specified attributes.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	prefix := strings.Join(h.groups, ".")
+	fields := make([]Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(fields, h.fields)
+	for _, a := range attrs {
+		fields = appendAttr(fields, prefix, a)
+	}
+
+	return &SlogHandler{logger: h.logger, fields: fields, groups: h.groups}
+}
+
+// WithGroup returns a new handler that qualifies the keys of all
+// subsequent attributes by the group name, as group.key.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &SlogHandler{
+		logger: h.logger,
+		fields: h.fields,
+		groups: append(groups, name),
+	}
+}
+
+// NewSlogOutput returns an output that passes messages to the
+// specified slog.Handler instead of writing them to a writer.
+// The fields of the message are passed as attributes of the record,
+// the prefix (if it is shown) is passed as the "prefix" attribute.
+//
+// The Layouts, TextStyle, WithColor and other formatting parameters
+// of the output are ignored - the handler formats the records itself.
+//
+// Example usage:
+//
+//	h := slog.NewJSONHandler(os.Stdout, nil)
+//	logger.SetOutputs(log.NewSlogOutput("slog", h))
+func NewSlogOutput(name string, h slog.Handler) Output {
+	return Output{
+		Name:   name,
+		Writer: &slogWriter{handler: h},
+		Levels: level.Default,
+	}
+}
+
+// The slogWriter is the writer of the output created by NewSlogOutput.
+// The logger passes messages to the handler directly, the writer is used
+// only for the data written to the output as is.
+type slogWriter struct {
+	handler slog.Handler
+}
+
+// Write passes p as the message of the Info record to the handler.
+func (w *slogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+	if err := w.handler.Handle(context.Background(), r); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// The emit passes the message to the handler with the ctx
+// of the message, so the handler can use its values.
+func (w *slogWriter) emit(
+	ctx context.Context,
+	p string,
+	l level.Level,
+	t time.Time,
+	sf *stackFrame,
+	fields []Field,
+	f string,
+	a []any,
+) error {
+	lvl := toSlogLevel(l)
+	if !w.handler.Enabled(ctx, lvl) {
+		return nil
+	}
+
+	msg := strings.TrimSuffix(formatMessage(f, a), "\n")
+	r := slog.NewRecord(t, lvl, msg, sf.PC)
+	if p != "" {
+		r.AddAttrs(slog.String("prefix", p))
+	}
+
+	for _, fd := range fields {
+		r.AddAttrs(slog.Any(fd.Key, fieldValue(fd.Value)))
+	}
+
+	return w.handler.Handle(ctx, r)
+}
+
+// The slogCaller returns the record with the caller of the slog method
+// to match the Filters of the outputs: the first function outside the
+// log/slog package and the handler.
+func slogCaller() *Record {
+	pc := make([]uintptr, 16)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log/slog.") &&
+			!strings.HasPrefix(frame.Function, slogHandlerFunc) {
+			return &Record{FilePath: frame.File, function: frame.Function}
+		}
+
+		if !more {
+			return &Record{}
+		}
+	}
+}
+
+// The appendAttr appends the attribute to the list of fields. The key
+// of the attribute is qualified by the prefix. Attributes of the group
+// kind are flattened as group.key, empty attributes are ignored.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	// Group: an empty key inlines the attributes of the group.
+	if a.Value.Kind() == slog.KindGroup {
+		key := prefix
+		if a.Key != "" {
+			key = joinKey(prefix, a.Key)
+		}
+
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, key, ga)
+		}
+
+		return fields
+	}
+
+	if a.Key == "" {
+		return fields
+	}
+
+	return append(fields, Field{
+		Key:   joinKey(prefix, a.Key),
+		Value: a.Value.Any(),
+	})
+}
+
+// The joinKey qualifies the key by the prefix.
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// The fromSlogLevel converts the slog level to the logger level.
+func fromSlogLevel(l slog.Level) level.Level {
+	switch {
+	case l < slog.LevelDebug:
+		return level.Trace
+	case l < slog.LevelInfo:
+		return level.Debug
+	case l < slog.LevelWarn:
+		return level.Info
+	case l < slog.LevelError:
+		return level.Warn
+	case l < SlogLevelFatal:
+		return level.Error
+	case l < SlogLevelPanic:
+		return level.Fatal
+	}
+
+	return level.Panic
+}
+
+// The toSlogLevel converts the logger level to the slog level.
+func toSlogLevel(l level.Level) slog.Level {
+	switch l {
+	case level.Panic:
+		return SlogLevelPanic
+	case level.Fatal:
+		return SlogLevelFatal
+	case level.Error:
+		return slog.LevelError
+	case level.Warn:
+		return slog.LevelWarn
+	case level.Debug:
+		return slog.LevelDebug
+	case level.Trace:
+		return SlogLevelTrace
+	}
+
+	return slog.LevelInfo
+}
@@ -0,0 +1,229 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// TestSlogHandler tests the SlogHandler.
+func TestSlogHandler(t *testing.T) {
+	logger := New("APP")
+	buf := &bytes.Buffer{}
+	logger.SetOutputs(Output{
+		Name:   "test",
+		Writer: buf,
+		Levels: level.Error | level.Warn,
+	})
+
+	sl := slog.New(NewSlogHandler(logger))
+	sl.Info("skipped")
+	if buf.Len() != 0 {
+		t.Errorf("the Info level should be skipped: %s", buf.String())
+	}
+
+	sl.With("user_id", 7).
+		WithGroup("req").
+		Warn("slow request", "id", 42, slog.Group("db", "rows", 3))
+
+	out := buf.String()
+	if !strings.Contains(out, "WARNING") ||
+		!strings.HasSuffix(out, "slow request user_id=7 req.id=42 req.db.rows=3\n") {
+		t.Errorf("incorrect message: %s", out)
+	}
+
+	if !strings.Contains(out, "slog_test.go") {
+		t.Errorf("the caller was not detected: %s", out)
+	}
+
+	// JSON style.
+	buf.Reset()
+	logger.EditOutputs(Output{Name: "test", TextStyle: trit.False})
+	sl.Error("failed", "err", "timeout")
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if obj["level"] != "ERROR" ||
+		obj["message"] != "failed" ||
+		obj["err"] != "timeout" {
+		t.Errorf("incorrect JSON message: %s", buf.String())
+	}
+}
+
+// TestSlogLevels tests the conversion of the levels.
+func TestSlogLevels(t *testing.T) {
+	levels := []level.Level{
+		level.Panic,
+		level.Fatal,
+		level.Error,
+		level.Warn,
+		level.Info,
+		level.Debug,
+		level.Trace,
+	}
+
+	for _, l := range levels {
+		if got := fromSlogLevel(toSlogLevel(l)); got != l {
+			t.Errorf("expected %s, got %s", level.Labels[l], level.Labels[got])
+		}
+	}
+
+	if got := fromSlogLevel(slog.LevelInfo + 1); got != level.Info {
+		t.Errorf("expected INFO, got %s", level.Labels[got])
+	}
+}
+
+// TestNewSlogOutput tests the output to the slog.Handler.
+func TestNewSlogOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: SlogLevelTrace,
+	})
+
+	logger := New("APP")
+	if err := logger.SetOutputs(NewSlogOutput("slog", h)); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.With("user_id", 7).Debugf("cache miss %s", "key")
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+
+	if obj["level"] != "DEBUG" ||
+		obj["msg"] != "cache miss key" ||
+		obj["prefix"] != "APP" ||
+		obj["user_id"] != float64(7) {
+		t.Errorf("incorrect record: %s", buf.String())
+	}
+}
+
+// TestSlogHandlerFilters tests that the Enabled method
+// matches the Filters of the outputs against the caller.
+func TestSlogHandlerFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   level.Level
+		filter   Filter
+		expected bool
+	}{
+		{
+			name:     "Filter enables the level",
+			levels:   level.Info,
+			filter:   Filter{File: "slog_test.go", Levels: level.Debug},
+			expected: true,
+		},
+		{
+			name:     "Filter disables the level",
+			levels:   level.Info | level.Debug,
+			filter:   Filter{File: "slog_test.go", Levels: level.Error},
+			expected: false,
+		},
+		{
+			name:     "Filter of another file",
+			levels:   level.Info,
+			filter:   Filter{File: "main.go", Levels: level.Debug},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := New()
+			logger.SetOutputs(Output{
+				Name:    "test",
+				Writer:  &bytes.Buffer{},
+				Levels:  tt.levels,
+				Filters: []Filter{tt.filter},
+			})
+
+			sl := slog.New(NewSlogHandler(logger))
+			got := sl.Enabled(context.Background(), slog.LevelDebug)
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// The ctxHandler is the slog.Handler that records
+// the value of the context key and returns the err.
+type ctxHandler struct {
+	values []any
+	err    error
+}
+
+func (h *ctxHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *ctxHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *ctxHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *ctxHandler) Handle(ctx context.Context, _ slog.Record) error {
+	h.values = append(h.values, ctx.Value(ctxKey("trace")))
+	return h.err
+}
+
+// The ctxKey is the type of the keys of the test contexts.
+type ctxKey string
+
+// TestSlogOutputContext tests that the context of the
+// message is passed to the handler of the slog output.
+func TestSlogOutputContext(t *testing.T) {
+	h := &ctxHandler{}
+	logger := New()
+	logger.SetOutputs(NewSlogOutput("slog", h))
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	logger.InfoContext(ctx, "request accepted")
+	slog.New(NewSlogHandler(logger)).InfoContext(ctx, "bridged")
+	logger.Info("no context")
+
+	expected := []any{"t1", "t1", nil}
+	if len(h.values) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, h.values)
+	}
+
+	for i, v := range expected {
+		if h.values[i] != v {
+			t.Errorf("%d: expected %v, got %v", i, v, h.values[i])
+		}
+	}
+}
+
+// TestSlogOutputErrors tests that the errors of the handler of the
+// slog output are reported and the record is passed to the fallback.
+func TestSlogOutputErrors(t *testing.T) {
+	rec := &errorRecorder{}
+	backup := &bytes.Buffer{}
+	logger := New()
+	logger.SetErrorHandler(rec.handle)
+
+	o := NewSlogOutput("slog", &ctxHandler{err: errors.New("closed")})
+	o.Fallback = "backup"
+	logger.SetOutputs(o, Output{Name: "backup", Writer: backup})
+
+	logger.Error("failed")
+	if errs := rec.list(); len(errs) != 1 || errs[0] != "slog: closed" {
+		t.Errorf("incorrect errors: %v", errs)
+	}
+
+	if o := logger.Outputs("slog")[0]; o.Errors() != 1 {
+		t.Errorf("expected 1 error, got %d", o.Errors())
+	}
+
+	if out := backup.String(); !strings.Contains(out, "failed") ||
+		!strings.Contains(out, FallbackField+"=slog") {
+		t.Errorf("incorrect fallback output: %s", out)
+	}
+}
@@ -0,0 +1,305 @@
+package syslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/level"
+)
+
+const (
+	// RFC5424 is the syslog protocol of RFC 5424 with
+	// the structured data, it's the default protocol.
+	RFC5424 Protocol = iota
+
+	// RFC3164 is the BSD syslog protocol of RFC 3164,
+	// the fields are added to the message as key=value.
+	RFC3164
+)
+
+// The facilities of the messages, see RFC 5424, section 6.2.1.
+const (
+	User Facility = iota + 1
+	Mail
+	Daemon
+	Auth
+	Syslog
+	LPR
+	News
+	UUCP
+	Cron
+	AuthPriv
+	FTP
+
+	Local0 Facility = iota + 5
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// The severities of the messages, see RFC 5424, section 6.2.1.
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+const (
+	// MsgIDField is the key of the field whose value is used
+	// as the MSGID of the message instead of Formatter.MsgID.
+	MsgIDField = "msgid"
+
+	// The defaultEnterpriseID is the private enterprise number
+	// reserved for documentation, see RFC 5612.
+	defaultEnterpriseID = "32473"
+
+	// The nilValue is the value of the empty header field.
+	nilValue = "-"
+
+	// The timestamp formats of the protocols.
+	rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+	rfc3164Time = "Jan _2 15:04:05"
+)
+
+// Severities associates the levels of the logger with the severities
+// of the syslog messages. The levels that are not in the map are sent
+// with the Debug severity.
+var Severities = map[level.Level]Severity{
+	level.Panic: Emergency,
+	level.Fatal: Critical,
+	level.Error: Error,
+	level.Warn:  Warning,
+	level.Info:  Informational,
+	level.Debug: Debug,
+	level.Trace: Debug,
+}
+
+// The hostname is the name of the host,
+// it's used by the Formatter by default.
+var hostname, _ = os.Hostname()
+
+// Protocol is the type of the syslog protocol.
+type Protocol int
+
+// Facility is the type of the facility of the messages.
+type Facility int
+
+// Severity is the type of the severity of the messages.
+type Severity int
+
+// Formatter is the log.Formatter that renders the records as syslog
+// messages. The message has no framing or trailing newline, the Writer
+// frames it for the transport.
+//
+// For RFC 5424 the fields of the record are sent as the structured data
+// element "fields@32473" (the enterprise number can be changed), and the
+// caller (according to the Layouts of the output) as the "caller@32473"
+// element with the file, line and func parameters.
+//
+// Example of the message:
+//
+//	<14>1 2023-06-26T11:42:08.000000Z host app 42 - [fields@32473 id="7"] hi
+type Formatter struct {
+	// Protocol is the syslog protocol: RFC5424 or RFC3164.
+	Protocol Protocol
+
+	// Facility is the facility of the messages, by default, User.
+	Facility Facility
+
+	// Hostname is the HOSTNAME of the messages,
+	// by default, the name of the host.
+	Hostname string
+
+	// AppName is the APP-NAME of the messages, by default, it's the
+	// prefix of the logger, or the name of the program without it.
+	AppName string
+
+	// MsgID is the MSGID of the messages, by default, there
+	// is no MSGID. The record can set it by the MsgIDField field.
+	MsgID string
+
+	// EnterpriseID is the private enterprise number of the SD-IDs
+	// of the structured data elements, by default, 32473.
+	EnterpriseID string
+}
+
+// Format renders the record as the syslog message.
+func (f Formatter) Format(r *log.Record, o *log.Output) ([]byte, error) {
+	severity, ok := Severities[r.Level]
+	if !ok {
+		severity = Debug
+	}
+
+	facility := f.Facility
+	if facility == 0 {
+		facility = User
+	}
+
+	appName := f.AppName
+	switch {
+	case appName != "":
+	case r.Prefix != "":
+		appName = r.Prefix
+	default:
+		appName = filepath.Base(os.Args[0])
+	}
+
+	msgID := f.MsgID
+	fields := make([]log.Field, 0, len(r.Fields))
+	for _, field := range r.Fields {
+		if field.Key == MsgIDField {
+			msgID = fmt.Sprint(field.Value)
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	host := f.Hostname
+	if host == "" {
+		host = hostname
+	}
+
+	// The trailing newline would split the message of the line-based
+	// collector, the structured data of RFC 3164 follows the message.
+	msg := strings.TrimRight(r.Message, "\n")
+
+	pri := int(facility)*8 + int(severity)
+	sb := strings.Builder{}
+	sb.WriteString("<" + strconv.Itoa(pri) + ">")
+
+	if f.Protocol == RFC3164 {
+		sb.WriteString(r.Time.Format(rfc3164Time))
+		sb.WriteString(" " + headerField(host, 255))
+		sb.WriteString(" " + headerField(appName, 32))
+		sb.WriteString("[" + strconv.Itoa(os.Getpid()) + "]: ")
+		sb.WriteString(msg)
+		for _, field := range append(callerFields(r, o), fields...) {
+			sb.WriteString(" " + paramName(field.Key) + "=" +
+				strconv.Quote(fmt.Sprint(field.Value)))
+		}
+
+		return []byte(sb.String()), nil
+	}
+
+	id := f.EnterpriseID
+	if id == "" {
+		id = defaultEnterpriseID
+	}
+
+	sb.WriteString("1 " + r.Time.Format(rfc5424Time))
+	sb.WriteString(" " + headerField(host, 255))
+	sb.WriteString(" " + headerField(appName, 48))
+	sb.WriteString(" " + strconv.Itoa(os.Getpid()))
+	sb.WriteString(" " + headerField(msgID, 32) + " ")
+
+	sd := structuredData(&sb, "fields@"+id, fields)
+	sd = structuredData(&sb, "caller@"+id, callerFields(r, o)) || sd
+	if !sd {
+		sb.WriteString(nilValue)
+	}
+
+	if msg != "" {
+		sb.WriteString(" " + msg)
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// The callerFields returns the caller of the record
+// according to the Layouts of the output.
+func callerFields(r *log.Record, o *log.Output) []log.Field {
+	var fields []log.Field
+	if o.Layouts.FilePath() {
+		fields = append(fields, log.Field{Key: "file", Value: r.FilePath})
+	}
+
+	if o.Layouts.LineNumber() {
+		fields = append(fields, log.Field{Key: "line", Value: r.LineNumber})
+	}
+
+	if o.Layouts.FuncName() {
+		fields = append(fields, log.Field{Key: "func", Value: r.FuncName})
+	}
+
+	return fields
+}
+
+// The structuredData writes the structured data element with the
+// fields as its parameters, it returns false if there are no fields.
+func structuredData(sb *strings.Builder, id string, fields []log.Field) bool {
+	if len(fields) == 0 {
+		return false
+	}
+
+	sb.WriteString("[" + id)
+	for _, field := range fields {
+		sb.WriteString(" " + paramName(field.Key) + `="`)
+		sb.WriteString(paramValue(fmt.Sprint(field.Value)) + `"`)
+	}
+
+	sb.WriteString("]")
+	return true
+}
+
+// The headerField returns the value of the header field: the printable
+// US-ASCII characters, at most n of them, or "-" for the empty value.
+func headerField(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+
+		return r
+	}, s)
+
+	switch {
+	case s == "":
+		return nilValue
+	case len(s) > n:
+		return s[:n]
+	}
+
+	return s
+}
+
+// The paramName returns the name of the structured data parameter:
+// the printable US-ASCII characters except '=', ']', '"' and space,
+// at most 32 of them.
+func paramName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+
+		return r
+	}, s)
+
+	switch {
+	case s == "":
+		return "_"
+	case len(s) > 32:
+		return s[:32]
+	}
+
+	return s
+}
+
+// The paramValue escapes the value of the structured
+// data parameter: '"', '\' and ']' are escaped by '\'.
+func paramValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
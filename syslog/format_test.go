@@ -0,0 +1,141 @@
+package syslog
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+)
+
+// TestFormatter tests the Format method of the Formatter.
+func TestFormatter(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	ts := time.Date(2023, 6, 26, 11, 42, 8, 5000, time.UTC)
+	record := func(l level.Level, fields ...log.Field) *log.Record {
+		return &log.Record{
+			Prefix:     "app",
+			Level:      l,
+			Time:       ts,
+			Message:    "request accepted",
+			Fields:     fields,
+			FilePath:   "/src/app/main.go",
+			LineNumber: 42,
+			FuncName:   "main",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		record    *log.Record
+		layouts   layout.Layout
+		expected  string
+	}{
+		{
+			name:      "RFC 5424 without structured data",
+			formatter: Formatter{Hostname: "host"},
+			record:    record(level.Info),
+			expected: "<14>1 2023-06-26T11:42:08.000005Z host app " + pid +
+				" - - request accepted",
+		},
+		{
+			name: "RFC 5424 with fields and MSGID",
+			formatter: Formatter{
+				Hostname: "host",
+				Facility: Local0,
+				MsgID:    "default",
+			},
+			record: record(level.Error,
+				log.Field{Key: "id", Value: 7},
+				log.Field{Key: "path", Value: `C:\tmp "x"]`},
+				log.Field{Key: MsgIDField, Value: "req"},
+			),
+			expected: "<131>1 2023-06-26T11:42:08.000005Z host app " + pid +
+				` req [fields@32473 id="7" path="C:\\tmp \"x\"\]"]` +
+				" request accepted",
+		},
+		{
+			name: "RFC 5424 with caller",
+			formatter: Formatter{
+				Hostname:     "my host",
+				AppName:      "billing",
+				EnterpriseID: "1234",
+			},
+			record:  record(level.Panic, log.Field{Key: "a=b", Value: true}),
+			layouts: layout.FullFilePath | layout.LineNumber | layout.FuncName,
+			expected: "<8>1 2023-06-26T11:42:08.000005Z my_host billing " +
+				pid + ` - [fields@1234 a_b="true"]` +
+				`[caller@1234 file="/src/app/main.go" line="42" func="main"]` +
+				" request accepted",
+		},
+		{
+			name:      "RFC 3164",
+			formatter: Formatter{Protocol: RFC3164, Hostname: "host"},
+			record:    record(level.Warn, log.Field{Key: "id", Value: 7}),
+			expected: "<12>Jun 26 11:42:08 host app[" + pid +
+				`]: request accepted id="7"`,
+		},
+		{
+			name:      "RFC 3164 with trailing newline",
+			formatter: Formatter{Protocol: RFC3164, Hostname: "host"},
+			record: &log.Record{
+				Prefix:  "app",
+				Level:   level.Info,
+				Time:    ts,
+				Message: "cache miss k\n",
+				Fields:  []log.Field{{Key: "id", Value: 7}},
+			},
+			expected: "<14>Jun 26 11:42:08 host app[" + pid +
+				`]: cache miss k id="7"`,
+		},
+		{
+			name:      "Trace level",
+			formatter: Formatter{Hostname: "host", Facility: Daemon},
+			record:    record(level.Trace),
+			expected: "<31>1 2023-06-26T11:42:08.000005Z host app " + pid +
+				" - - request accepted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &log.Output{Layouts: test.layouts}
+
+			data, err := test.formatter.Format(test.record, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(data); got != test.expected {
+				t.Errorf("incorrect message:\n got: %s\nwant: %s",
+					got, test.expected)
+			}
+		})
+	}
+}
+
+// TestHeaderField tests headerField function.
+func TestHeaderField(t *testing.T) {
+	tests := []struct {
+		value    string
+		n        int
+		expected string
+	}{
+		{"", 10, "-"},
+		{"host", 10, "host"},
+		{"my host", 10, "my_host"},
+		{"very-long-name", 4, "very"},
+		{"ünïcode", 10, "_n_code"},
+	}
+
+	for _, test := range tests {
+		if got := headerField(test.value, test.n); got != test.expected {
+			t.Errorf("headerField(%q, %d) = %q, expected %q",
+				test.value, test.n, got, test.expected)
+		}
+	}
+}
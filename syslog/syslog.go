@@ -0,0 +1,268 @@
+// Package syslog provides the syslog output for the logger: the
+// Formatter renders the records as RFC 5424 or RFC 3164 messages,
+// and the Writer sends them to the local syslog daemon (/dev/log)
+// or to the remote one over UDP or TCP.
+//
+// Example usage:
+//
+//	w := &syslog.Writer{Network: "tcp", Address: "syslog.local:601"}
+//	defer w.Close()
+//
+//	logger.SetOutputs(log.Output{
+//	    Name:      "syslog",
+//	    Writer:    w,
+//	    Formatter: syslog.Formatter{Facility: syslog.Local0},
+//	})
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Auto selects the framing by the network: the octet counting
+	// for TCP, the newline for the unix stream socket, and no framing
+	// for the datagram sockets.
+	Auto Framing = iota
+
+	// OctetCounting prefixes the message with its length
+	// and a space, see RFC 6587, section 3.4.1.
+	OctetCounting
+
+	// NonTransparent terminates the message
+	// with a newline, see RFC 6587, section 3.4.2.
+	NonTransparent
+
+	// NoFraming sends the message as is, it's
+	// used for the datagram sockets (UDP, unixgram).
+	NoFraming
+)
+
+const (
+	// The defaultTimeout is the default timeout of
+	// the connection and of the write.
+	defaultTimeout = 5 * time.Second
+
+	// The defaultBackoff and defaultMaxBackoff are the default
+	// intervals between the attempts to connect.
+	defaultBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// The localAddresses is the list of the sockets of the local
+// syslog daemon on the different systems.
+var localAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// The currentTime returns the current time.
+// Redefined this function to be able to test the backoff.
+var currentTime = time.Now
+
+// ErrClosed is returned by the Write of the closed Writer.
+var ErrClosed = errors.New("syslog: writer is closed")
+
+// Framing is the type of the framing of the messages
+// in the stream, see RFC 6587.
+type Framing int
+
+// Writer is the io.WriteCloser that sends each written message to the
+// syslog daemon, the message must be formatted by the Formatter. The
+// connection is established on the first write, and is reestablished
+// with the exponential backoff when it fails. The Writer is safe for
+// concurrent use.
+type Writer struct {
+	// Network is the network of the syslog daemon: "udp", "tcp",
+	// "unix", "unixgram" (or their variants, e.g. "tcp4"). The empty
+	// network is the local syslog daemon, then the Address is the path
+	// to its socket, by default /dev/log (or /var/run/syslog etc.).
+	Network string
+
+	// Address is the address of the syslog daemon, e.g. "localhost:514"
+	// for UDP, "localhost:601" for TCP or "/dev/log" for unix sockets.
+	Address string
+
+	// Framing is the framing of the messages, by default,
+	// it's selected by the Network, see Auto.
+	Framing Framing
+
+	// Timeout is the timeout of the connection and of the write.
+	// The zero value is 5s.
+	Timeout time.Duration
+
+	// Backoff is the interval after the first failed attempt to
+	// connect, it's doubled after each failed attempt up to MaxBackoff.
+	// The writes during the backoff fail without the connection attempt.
+	// The zero values are 100ms and 30s.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	conn    net.Conn
+	framing Framing       // framing of the current connection
+	backoff time.Duration // current interval between the attempts
+	retryAt time.Time     // time of the next attempt to connect
+	lastErr error         // error of the last attempt to connect
+	closed  bool
+
+	mu sync.Mutex
+}
+
+// Write sends p as one message. If the connection fails, the Writer
+// reconnects and sends the message again once.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return 0, err
+			}
+		}
+
+		if err = w.send(p); err == nil {
+			return len(p), nil
+		}
+
+		// The broken connection is reestablished immediately.
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	return 0, err
+}
+
+// Close closes the connection, further writes return ErrClosed.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// The connect connects to the syslog daemon, it fails
+// without the attempt during the backoff.
+//
+// The method must be called with the writer's lock held.
+func (w *Writer) connect() error {
+	if now := currentTime(); now.Before(w.retryAt) {
+		return fmt.Errorf("syslog: reconnect in %s: %w",
+			w.retryAt.Sub(now).Round(time.Millisecond), w.lastErr)
+	}
+
+	conn, network, err := w.dial()
+	if err != nil {
+		first := w.Backoff
+		if first <= 0 {
+			first = defaultBackoff
+		}
+
+		limit := w.MaxBackoff
+		if limit <= 0 {
+			limit = defaultMaxBackoff
+		}
+
+		w.backoff = min(max(2*w.backoff, first), max(first, limit))
+		w.retryAt = currentTime().Add(w.backoff)
+		w.lastErr = err
+		return err
+	}
+
+	w.conn, w.backoff, w.retryAt, w.lastErr = conn, 0, time.Time{}, nil
+	w.framing = w.Framing
+	if w.framing == Auto {
+		w.framing = autoFraming(network)
+	}
+
+	return nil
+}
+
+// The dial connects to the syslog daemon, it returns
+// the connection and its network.
+func (w *Writer) dial() (net.Conn, string, error) {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	if w.Network != "" {
+		conn, err := net.DialTimeout(w.Network, w.Address, timeout)
+		return conn, w.Network, err
+	}
+
+	// The local syslog daemon.
+	addresses := localAddresses
+	if w.Address != "" {
+		addresses = []string{w.Address}
+	}
+
+	var errs []error
+	for _, addr := range addresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, addr, timeout)
+			if err == nil {
+				return conn, network, nil
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return nil, "", fmt.Errorf("syslog: local daemon: %w", errors.Join(errs...))
+}
+
+// The send writes the framed message to the connection.
+//
+// The method must be called with the writer's lock held.
+func (w *Writer) send(p []byte) error {
+	var msg []byte
+	switch w.framing {
+	case OctetCounting:
+		msg = append(strconv.AppendInt(nil, int64(len(p)), 10), ' ')
+		msg = append(msg, p...)
+	case NonTransparent:
+		msg = append(append(msg, p...), '\n')
+	default:
+		msg = p
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	w.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// The autoFraming returns the framing of the messages for the network.
+func autoFraming(network string) Framing {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return OctetCounting
+	case "unix":
+		return NonTransparent
+	}
+
+	return NoFraming
+}
@@ -0,0 +1,314 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goloop/log"
+	"github.com/goloop/log/level"
+)
+
+// TestWriterDatagram tests the Writer over the datagram sockets.
+func TestWriterDatagram(t *testing.T) {
+	tests := []struct {
+		name   string
+		listen func(t *testing.T) net.PacketConn
+		writer func(addr string) *Writer
+	}{
+		{
+			name: "UDP",
+			listen: func(t *testing.T) net.PacketConn {
+				conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return conn
+			},
+			writer: func(addr string) *Writer {
+				return &Writer{Network: "udp", Address: addr}
+			},
+		},
+		{
+			name: "Local daemon",
+			listen: func(t *testing.T) net.PacketConn {
+				path := filepath.Join(t.TempDir(), "log.sock")
+				conn, err := net.ListenPacket("unixgram", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return conn
+			},
+			writer: func(addr string) *Writer {
+				return &Writer{Address: addr}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := test.listen(t)
+			defer conn.Close()
+
+			w := test.writer(conn.LocalAddr().String())
+			defer w.Close()
+
+			for _, msg := range []string{"<14>1 first", "<14>1 second"} {
+				if _, err := w.Write([]byte(msg)); err != nil {
+					t.Fatal(err)
+				}
+
+				buf := make([]byte, 1024)
+				conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := string(buf[:n]); got != msg {
+					t.Errorf("expected %q, got %q", msg, got)
+				}
+			}
+		})
+	}
+}
+
+// TestWriterStream tests the framing of the messages in the stream.
+func TestWriterStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  string
+		framing  Framing
+		expected string
+	}{
+		{
+			name:     "TCP",
+			network:  "tcp",
+			expected: "11 <14>1 first10 <14>1 next",
+		},
+		{
+			name:     "TCP with newline",
+			network:  "tcp",
+			framing:  NonTransparent,
+			expected: "<14>1 first\n<14>1 next\n",
+		},
+		{
+			name:     "Unix stream",
+			network:  "unix",
+			expected: "<14>1 first\n<14>1 next\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if test.network == "unix" {
+				addr = filepath.Join(t.TempDir(), "log.sock")
+			}
+
+			ln, err := net.Listen(test.network, addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+
+			w := &Writer{
+				Network: test.network,
+				Address: ln.Addr().String(),
+				Framing: test.framing,
+			}
+			w.Write([]byte("<14>1 first"))
+			w.Write([]byte("<14>1 next"))
+			w.Close()
+
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			buf := make([]byte, len(test.expected))
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(buf); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestWriterReconnect tests the reconnection after the connection fails.
+func TestWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// The server closes the first connection after the first
+	// message and reads the messages from the next one.
+	received := make(chan string, 16)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			r := bufio.NewReader(conn)
+			for {
+				size, err := r.ReadString(' ')
+				if err != nil {
+					break
+				}
+
+				n, _ := strconv.Atoi(strings.TrimSpace(size))
+				msg := make([]byte, n)
+				if _, err := io.ReadFull(r, msg); err != nil {
+					break
+				}
+
+				received <- strconv.Itoa(i) + ":" + string(msg)
+				if i == 0 {
+					break
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	w := &Writer{Network: "tcp", Address: ln.Addr().String()}
+	defer w.Close()
+
+	w.Write([]byte("first"))
+	if got := <-received; got != "0:first" {
+		t.Fatalf("unexpected message %q", got)
+	}
+
+	// The writes to the closed connection can succeed before
+	// the error is detected, the writer reconnects after it.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		w.Write([]byte("next"))
+		select {
+		case got := <-received:
+			if got != "1:next" {
+				t.Fatalf("unexpected message %q", got)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	t.Fatal("the writer didn't reconnect")
+}
+
+// TestWriterBackoff tests the backoff of the connection attempts.
+func TestWriterBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // nothing listens on the address
+
+	now := time.Now()
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = time.Now }()
+
+	w := &Writer{Network: "tcp", Address: addr, MaxBackoff: time.Second}
+	defer w.Close()
+
+	// The interval is doubled after each failed attempt.
+	for _, backoff := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		if _, err := w.Write([]byte("msg")); err == nil ||
+			strings.Contains(err.Error(), "reconnect in") {
+			t.Fatalf("expected the connection error, got %v", err)
+		}
+
+		if w.backoff != backoff {
+			t.Fatalf("expected backoff %s, got %s", backoff, w.backoff)
+		}
+
+		// No attempts during the backoff.
+		_, err := w.Write([]byte("msg"))
+		if err == nil || !strings.Contains(err.Error(), "reconnect in") {
+			t.Fatalf("expected the backoff error, got %v", err)
+		}
+
+		now = now.Add(backoff)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("msg")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// TestLoggerOutput tests the syslog output of the logger.
+func TestLoggerOutput(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w := &Writer{Network: "udp", Address: conn.LocalAddr().String()}
+	logger := log.New("app")
+	logger.SetOutputs(log.Output{
+		Name:      "syslog",
+		Writer:    w,
+		Levels:    level.Default,
+		Formatter: Formatter{Hostname: "host"},
+	})
+	defer logger.Close()
+
+	logger.Infow("request accepted", "id", 7)
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := string(buf[:n])
+	header := " host app " + strconv.Itoa(os.Getpid()) +
+		` - [fields@32473 id="7"]`
+	if !strings.HasPrefix(msg, "<14>1 ") || !strings.Contains(msg, header) ||
+		!strings.HasSuffix(msg, "] request accepted") {
+		t.Errorf("incorrect message: %s", msg)
+	}
+
+	// The trailing newline of the printf-like message is trimmed.
+	logger.Infof("cache miss %s\n", "k")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err = conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := string(buf[:n]); !strings.HasSuffix(msg, "] cache miss k") {
+		t.Errorf("incorrect message: %q", msg)
+	}
+}
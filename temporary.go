@@ -0,0 +1,153 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// The tempChange is the pending revert of the temporary
+// change of the output.
+type tempChange struct {
+	snapshot *Output // the output before the change, nil if it didn't exist
+	timer    *time.Timer
+	expires  time.Time
+}
+
+// The changeOutputs edits (see Output.configure) and deletes (see
+// DeleteOutputs) the outputs all at once: if an output isn't found or
+// the fallbacks of the result are incorrect, nothing is changed. If
+// the ttl is positive, the changed outputs are restored after the ttl,
+// otherwise the change is permanent and cancels the pending reverts
+// of the changed outputs.
+//
+// The reverts restore the outputs as they were before the first
+// temporary change: a new temporary change of the output extends
+// the time of the revert.
+func (logger *Logger) changeOutputs(
+	edits []Output,
+	deletes []string,
+	ttl time.Duration,
+) error {
+	logger.tmu.Lock()
+	defer logger.tmu.Unlock()
+
+	// Snapshot of the changed outputs.
+	snapshot := make(map[string]*Output, len(edits)+len(deletes))
+	err := logger.updateOutputs(func(outputs map[string]*Output) error {
+		for _, o := range edits {
+			current, ok := outputs[o.Name]
+			if !ok {
+				return fmt.Errorf("output not found '%s'", o.Name)
+			}
+
+			if _, ok := snapshot[o.Name]; !ok {
+				snapshot[o.Name] = current
+				edited := *current
+				current = &edited
+				outputs[o.Name] = current
+			}
+
+			current.configure(o)
+		}
+
+		for _, name := range deletes {
+			current, ok := outputs[name]
+			if !ok {
+				return fmt.Errorf("output not found '%s'", name)
+			}
+
+			if _, ok := snapshot[name]; !ok {
+				snapshot[name] = current
+			}
+
+			delete(outputs, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Schedule or cancel the reverts.
+	if logger.temporary == nil {
+		logger.temporary = make(map[string]*tempChange)
+	}
+
+	for name, o := range snapshot {
+		c, ok := logger.temporary[name]
+		if ok {
+			c.timer.Stop()
+			delete(logger.temporary, name)
+		}
+
+		if ttl <= 0 {
+			continue
+		}
+
+		if !ok {
+			c = &tempChange{snapshot: o}
+		}
+
+		name := name // captured by the closure below
+		c.expires = time.Now().Add(ttl)
+		c.timer = time.AfterFunc(ttl, func() {
+			logger.revertChange(name, c)
+		})
+		logger.temporary[name] = c
+	}
+
+	return nil
+}
+
+// The revertChange restores the output changed temporarily
+// if the change is still pending.
+func (logger *Logger) revertChange(name string, c *tempChange) bool {
+	logger.tmu.Lock()
+	if logger.temporary[name] != c {
+		logger.tmu.Unlock()
+		return false
+	}
+
+	c.timer.Stop()
+	delete(logger.temporary, name)
+	err := logger.restoreOutput(name, c.snapshot)
+	logger.tmu.Unlock()
+
+	if err != nil {
+		logger.Errorf("log: temporary change of the output %s "+
+			"not reverted: %v\n", name, err)
+		return false
+	}
+
+	logger.Infof("log: temporary change of the output %s reverted\n", name)
+	return true
+}
+
+// The restoreOutput sets the output as it was in the snapshot. It
+// returns an error and doesn't change the output if the snapshot is
+// incorrect for the current outputs, e.g. its fallback was deleted.
+func (logger *Logger) restoreOutput(name string, snapshot *Output) error {
+	return logger.updateOutputs(func(outputs map[string]*Output) error {
+		o := *snapshot
+		if o.Name != name || o.Writer == nil {
+			return fmt.Errorf("incorrect snapshot of the output '%s'", name)
+		}
+
+		outputs[name] = &o
+		return nil
+	})
+}
+
+// The revertTime returns the time when the temporary
+// change of the output will be reverted.
+func (logger *Logger) revertTime(name string) (time.Time, bool) {
+	logger.tmu.Lock()
+	defer logger.tmu.Unlock()
+
+	if c, ok := logger.temporary[name]; ok {
+		return c.expires, true
+	}
+
+	return time.Time{}, false
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/goloop/g"
 	"github.com/goloop/log/level"
 )
 
@@ -20,6 +19,8 @@ type stackFrame struct {
 	FuncName    string  // function name
 	FuncAddress uintptr // address of the function
 	FilePath    string  // file path
+	PC          uintptr // program counter
+	Function    string  // full name of the function with the package path
 }
 
 // The ioCopy function is used to copy the output of a reader
@@ -33,25 +34,48 @@ func ioCopy(r io.Reader, c chan string) {
 // The getStackFrame returns the stack slice. The skip argument
 // is the number of stack frames to skip before taking a slice.
 func getStackFrame(skip int) *stackFrame {
-	sf := &stackFrame{}
-
 	// Return program counters of function invocations on
 	// the calling goroutine's stack and skipping function
 	// call frames inside *Log.
-	pc := make([]uintptr, skip+1) // program counters
-	runtime.Callers(skip, pc)
+	pc := make([]uintptr, 1) // program counter
+	if runtime.Callers(skip, pc) == 0 {
+		panic(fmt.Sprintf("log: the stack has less than %d frames", skip))
+	}
+
+	return getStackFrameByPC(pc[0])
+}
 
-	// Get a function at an address on the stack.
-	fn := runtime.FuncForPC(pc[0])
+// The getStackFrameByPC returns the stack frame for the specified program
+// counter, e.g. for the PC of the slog.Record. If the function for the
+// program counter cannot be found, an empty frame is returned.
+//
+// The frame is resolved by runtime.CallersFrames, so the caller of the
+// function that was inlined is reported correctly.
+func getStackFrameByPC(pc uintptr) *stackFrame {
+	sf := &stackFrame{PC: pc}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function == "" {
+		return sf
+	}
 
 	// Get name, path and line of the file.
-	sf.FuncName = fn.Name()
-	sf.FuncAddress = fn.Entry()
-	sf.FilePath, sf.FileLine = fn.FileLine(pc[0])
+	sf.Function = frame.Function
+	sf.FuncName = frame.Function
+	sf.FuncAddress = frame.Entry
+	sf.FilePath, sf.FileLine = frame.File, frame.Line
 	if r := strings.Split(sf.FuncName, "."); len(r) > 0 {
 		sf.FuncName = r[len(r)-1]
 	}
 
+	// The entry of the inlined function is unknown,
+	// the address of the function it's inlined into is used.
+	if sf.FuncAddress == 0 {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			sf.FuncAddress = fn.Entry()
+		}
+	}
+
 	return sf
 }
 
@@ -69,13 +93,37 @@ func cutFilePath(n int, path string) string {
 	return ".../" + strings.Join(sections[len(sections)-n:], "/")
 }
 
-// The textMessage creates a text message.
+// The formatMessage creates the user's message according to the format
+// type: print, println or printf. For println-type formats the message
+// contains a newline character at the end.
+//
+// Note: the arguments are passed as a slice, not as a variadic parameter,
+// so this function (and the functions that call it) is not considered as
+// a printf wrapper with a format string.
+func formatMessage(f string, a []any) string {
+	switch {
+	case f == "":
+		fallthrough
+	case f == formatPrint:
+		return fmt.Sprint(a...)
+	case f == formatPrintln:
+		return fmt.Sprintln(a...)
+	}
+
+	return fmt.Sprintf(f, a...)
+}
+
+// The textMessage creates a text message. The header fields (the
+// fields of the context) are rendered before the user's message,
+// the other fields after it.
 func textMessage(
 	p string,
 	l level.Level,
 	t time.Time,
 	o *Output,
 	sf *stackFrame,
+	header []Field,
+	fields []Field,
 	f string,
 	a ...any,
 ) string {
@@ -141,37 +189,50 @@ func textMessage(
 		sb.WriteString(fmt.Sprintf("%#x%s", sf.FuncAddress, o.Space))
 	}
 
+	// Fields of the context.
+	if len(header) != 0 {
+		sb.WriteString(textFields(o.Space, header))
+		sb.WriteString(o.Space)
+	}
+
 	// Add message formatting.
-	var msg string
+	// For messages that are output on the same line (print-type), the task
+	// of separating the messages falls on the user. We don't need to add
+	// extra characters to user messages.
+	msg := formatMessage(f, a)
+
+	// Fields are added after the message, but before the
+	// trailing newline if the message has one.
+	if len(fields) != 0 {
+		body := strings.TrimSuffix(msg, "\n")
+		if body != "" {
+			body += o.Space
+		}
 
-	switch {
-	case f == "":
-		fallthrough
-	case f == formatPrint:
-		// For messages that are output on the same line, the task of
-		// separating the messages falls on the user. We don't need to
-		// add extra characters to user messages.
-		// msg = fmt.Sprintf("%s%s%s", sb.String(), fmt.Sprint(a...), o.Space)
-		msg = fmt.Sprintf("%s%s", sb.String(), fmt.Sprint(a...))
-	case f == formatPrintln:
-		msg = fmt.Sprintf("%s%s", sb.String(), fmt.Sprintln(a...))
-	default:
-		msg = fmt.Sprintf("%s%s", sb.String(), fmt.Sprintf(f, a...))
+		body += textFields(o.Space, fields)
+		if strings.HasSuffix(msg, "\n") {
+			body += "\n"
+		}
+
+		msg = body
 	}
 
-	return msg
+	sb.WriteString(msg)
+	return sb.String()
 }
 
-// The objectMessage creates a JSON message.
+// The objectMessage creates a JSON message. It returns an error
+// if the object cannot be marshaled.
 func objectMessage(
 	p string,
 	l level.Level,
 	t time.Time,
 	o *Output,
 	sf *stackFrame,
+	fields []Field,
 	f string,
 	a ...any,
-) string {
+) (string, error) {
 	// Output object.
 	// A general structure for outputting a log in JSON format.
 	obj := struct {
@@ -218,22 +279,21 @@ func objectMessage(
 		obj.LineNumber = sf.FileLine
 	}
 
-	// Clean message for default -ln format.
 	// Add message formatting.
-	switch {
-	case f == "":
-		fallthrough
-	case f == formatPrint:
-		obj.Message = fmt.Sprint(a...)
-	case f == formatPrintln:
-		obj.Message = strings.TrimSuffix(fmt.Sprintln(a...), "\n")
-	default:
-		obj.Message = fmt.Sprintf(f, a...)
+	// Clean message for default -ln format.
+	obj.Message = formatMessage(f, a)
+	if f == formatPrintln {
+		obj.Message = strings.TrimSuffix(obj.Message, "\n")
 	}
 
 	// Marshal object to JSON.
+	// Fields are added as top-level keys of the object.
 	data, err := json.Marshal(obj)
-	data = g.If(err != nil, []byte{}, data)
+	if err != nil {
+		return "", err
+	}
+
+	data = objectFields(data, fields)
 
 	// Add JSON formatting.
 	var msg string
@@ -251,7 +311,7 @@ func objectMessage(
 		msg += o.Space
 	}
 
-	return msg
+	return msg, nil
 }
 
 /*
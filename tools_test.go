@@ -166,6 +166,8 @@ func TestTextMessage(t *testing.T) {
 				timestamp,
 				output,
 				stackframe,
+				nil,
+				nil,
 				test.f,
 				test.a...,
 			)
@@ -186,6 +188,8 @@ func TestTextMessage(t *testing.T) {
 				timestamp,
 				output,
 				stackframe,
+				nil,
+				nil,
 				test.f,
 				test.a...,
 			)
@@ -206,6 +210,8 @@ func TestTextMessage(t *testing.T) {
 				timestamp,
 				output,
 				stackframe,
+				nil,
+				nil,
 				test.f,
 				test.a...,
 			)
@@ -280,19 +286,23 @@ func TestObjectMessage(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := objectMessage(
+			result, err := objectMessage(
 				prefix,
 				level,
 				timestamp,
 				output,
 				stackframe,
+				nil,
 				test.f,
 				test.a...,
 			)
+			if err != nil {
+				t.Fatalf("Failed to create message: %v", err)
+			}
 
 			// Unmarshal the JSON result into a map
 			var resultObj map[string]interface{}
-			err := json.Unmarshal([]byte(result), &resultObj)
+			err = json.Unmarshal([]byte(result), &resultObj)
 			if err != nil {
 				t.Fatalf("Failed to unmarshal JSON: %v", err)
 			}
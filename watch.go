@@ -0,0 +1,364 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goloop/trit"
+)
+
+// The watchInterval is the default interval of
+// the polling of the watched configuration file.
+const watchInterval = time.Second
+
+// Watcher reloads the configuration of the outputs of the logger
+// from the file, see Logger.WatchConfig.
+type Watcher struct {
+	logger   *Logger
+	path     string
+	interval time.Duration
+
+	data    []byte    // content of the last applied file
+	modTime time.Time // modification time of the last read file
+	size    int64     // size of the last read file
+	err     error     // error of the last reload
+
+	// The base is the outputs and the prefix as they were before the
+	// watcher changed them, the last is the edits of the outputs and
+	// the prefix of the last applied file: the parameters removed
+	// from the file are restored to their base values.
+	base       map[string]Output
+	basePrefix string
+	last       map[string]Output
+	lastPrefix string
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+	mu   sync.Mutex
+}
+
+// WatchConfig applies the configuration of the outputs from the JSON
+// file (see Config) and polls the file for changes with the specified
+// interval (one second if the interval is not positive).
+//
+// The changes are applied all at once or not at all. The outputs of the
+// file must be set in the logger, the omitted parameters don't change
+// the outputs, the destinations of the outputs are not reopened. If the
+// prefix of the file is not empty, it's set as the prefix of the logger.
+// The parameters (and the outputs, and the prefix) removed from the file
+// are restored to the values they had before the watcher changed them.
+// The environment variables override the file, see FromConfig.
+//
+// Each applied change is logged at the Info level with the list of
+// the modified parameters. Errors of reading and parsing of the file
+// are logged at the Error level, the current configuration is kept.
+//
+// The function returns an error if the file cannot be applied at the
+// start. Use the Close method of the watcher to stop watching.
+//
+// Example usage:
+//
+//	w, err := logger.WatchConfig("/etc/app/log.json", 5*time.Second)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer w.Close()
+func (logger *Logger) WatchConfig(
+	path string,
+	interval time.Duration,
+) (*Watcher, error) {
+	if interval <= 0 {
+		interval = watchInterval
+	}
+
+	w := &Watcher{
+		logger:   logger,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Reload checks the file immediately and applies it if it was changed.
+// It returns the error of the reading or applying of the file.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.err = w.reload()
+	return w.err
+}
+
+// Err returns the error of the last reload, or nil.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close stops watching the file.
+func (w *Watcher) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+	return nil
+}
+
+// The run polls the file until the watcher is closed.
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		w.mu.Lock()
+		err := w.reload()
+		if err != nil && (w.err == nil || err.Error() != w.err.Error()) {
+			// The same error is reported once.
+			w.logger.Errorf("log: config %s: %v\n", w.path, err)
+		}
+
+		w.err = err
+		w.mu.Unlock()
+	}
+}
+
+// The reload reads the file and applies it if the content was changed.
+// The method must be called with the watcher's lock held.
+func (w *Watcher) reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+
+	// The file was not modified since the last reading.
+	if w.data != nil && info.ModTime().Equal(w.modTime) &&
+		info.Size() == w.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.modTime, w.size = info.ModTime(), info.Size()
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return nil
+	}
+
+	if err := w.apply(data); err != nil {
+		return err
+	}
+
+	w.data = data
+	return nil
+}
+
+// The apply applies the content of the file to the logger
+// and logs the changes.
+func (w *Watcher) apply(data []byte) error {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+
+	cfg, err := overlayEnv(cfg, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+
+	edits := make(map[string]Output, len(cfg.Outputs))
+	for _, oc := range cfg.Outputs {
+		o, err := oc.edit()
+		if err != nil {
+			return err
+		}
+
+		edits[oc.Name] = o
+	}
+
+	// The base values of the parameters are kept until the output
+	// is removed from the file.
+	base := make(map[string]Output, len(edits))
+	before := w.logger.Outputs()
+	err = w.logger.updateOutputs(func(outputs map[string]*Output) error {
+		for name := range edits {
+			if _, ok := outputs[name]; !ok {
+				return fmt.Errorf("output not found '%s'", name)
+			}
+		}
+
+		for name, current := range outputs {
+			edit, listed := edits[name]
+			last, wasListed := w.last[name]
+			if !listed && !wasListed {
+				continue
+			}
+
+			b, ok := w.base[name]
+			if !ok {
+				b = *current
+			}
+
+			if listed {
+				base[name] = b
+			}
+
+			o := *current
+			o.reconfigure(b, last, edit)
+			outputs[name] = &o
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	changes := outputChanges(before, w.logger.Outputs())
+
+	// The prefix is changed by the same rules as the outputs.
+	prefix := w.logger.Prefix()
+	if w.data == nil {
+		w.basePrefix = prefix
+	}
+
+	next := prefix
+	reconfigureValue(&next, w.basePrefix, w.lastPrefix, cfg.Prefix)
+	if next != prefix {
+		w.logger.SetPrefix(next)
+		changes = append(changes,
+			fmt.Sprintf("prefix: %q -> %q", prefix, next))
+	}
+
+	w.base, w.last, w.lastPrefix = base, edits, cfg.Prefix
+	if len(changes) != 0 {
+		w.logger.Infof("log: config %s applied: %s\n",
+			w.path, strings.Join(changes, ", "))
+	}
+
+	return nil
+}
+
+// The reconfigure applies the edit created from the configuration (see
+// Output.configure) to the output. The parameters of the last edit that
+// are omitted in the new one are restored to the values of the base.
+func (o *Output) reconfigure(base, last, edit Output) {
+	reconfigureValue(&o.Levels, base.Levels, last.Levels, edit.Levels)
+	reconfigureValue(&o.Layouts, base.Layouts, last.Layouts, edit.Layouts)
+	reconfigureValue(&o.Space, base.Space, last.Space, edit.Space)
+	reconfigureValue(&o.WithPrefix, base.WithPrefix, last.WithPrefix,
+		edit.WithPrefix)
+	reconfigureValue(&o.WithColor, base.WithColor, last.WithColor,
+		edit.WithColor)
+	reconfigureValue(&o.Enabled, base.Enabled, last.Enabled, edit.Enabled)
+	reconfigureValue(&o.TextStyle, base.TextStyle, last.TextStyle,
+		edit.TextStyle)
+	reconfigureValue(&o.Formatter, base.Formatter, last.Formatter,
+		edit.Formatter)
+	reconfigureValue(&o.TimestampFormat, base.TimestampFormat,
+		last.TimestampFormat, edit.TimestampFormat)
+	reconfigureValue(&o.LevelFormat, base.LevelFormat, last.LevelFormat,
+		edit.LevelFormat)
+	reconfigureValue(&o.Async, base.Async, last.Async, edit.Async)
+	reconfigureValue(&o.BufferSize, base.BufferSize, last.BufferSize,
+		edit.BufferSize)
+	reconfigureValue(&o.Fallback, base.Fallback, last.Fallback, edit.Fallback)
+
+	// The text and json formats replace the formatter.
+	if edit.TextStyle != trit.Unknown {
+		o.Formatter = nil
+	}
+}
+
+// The reconfigureValue sets the value of the edit if it's not empty,
+// or restores the base value if the value was set by the last edit.
+func reconfigureValue[T comparable](value *T, base, last, edit T) {
+	var zero T
+	switch {
+	case edit != zero:
+		*value = edit
+	case last != zero:
+		*value = base
+	}
+}
+
+// The outputChanges returns the descriptions of the changed
+// parameters of the outputs, e.g. "stdout.levels: info -> >=warn".
+func outputChanges(before, after []Output) []string {
+	var result []string
+	old := make(map[string]Output, len(before))
+	for _, o := range before {
+		old[o.Name] = o
+	}
+
+	// The outputs are listed in the order of names.
+	slices.SortFunc(after, func(a, b Output) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, a := range after {
+		b, ok := old[a.Name]
+		if !ok {
+			continue
+		}
+
+		add := func(param string, from, to any) {
+			if !reflect.DeepEqual(from, to) {
+				result = append(result,
+					fmt.Sprintf("%s.%s: %v -> %v", a.Name, param, from, to))
+			}
+		}
+
+		add("levels", b.Levels, a.Levels)
+		add("layouts", b.Layouts, a.Layouts)
+		add("enabled", b.Enabled.IsTrue(), a.Enabled.IsTrue())
+		add("format", outputFormat(&b), outputFormat(&a))
+		add("withPrefix", b.WithPrefix.IsTrue(), a.WithPrefix.IsTrue())
+		add("withColor", b.WithColor.IsTrue(), a.WithColor.IsTrue())
+		add("space", fmt.Sprintf("%q", b.Space), fmt.Sprintf("%q", a.Space))
+		add("timestampFormat", b.TimestampFormat, a.TimestampFormat)
+		add("levelFormat", b.LevelFormat, a.LevelFormat)
+		add("async", b.Async.IsTrue(), a.Async.IsTrue())
+		add("bufferSize", b.BufferSize, a.BufferSize)
+	}
+
+	return result
+}
+
+// The outputFormat returns the name of the format of the output.
+func outputFormat(o *Output) string {
+	switch f := o.formatter().(type) {
+	case TextFormatter:
+		return "text"
+	case JSONFormatter:
+		return "json"
+	case LogfmtFormatter:
+		return "logfmt"
+	default:
+		return fmt.Sprintf("%T", f)
+	}
+}
@@ -0,0 +1,218 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goloop/log/layout"
+	"github.com/goloop/log/level"
+	"github.com/goloop/trit"
+)
+
+// The syncBuffer is the buffer that is safe for concurrent use.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+// Write writes p to the buffer.
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the contents of the buffer.
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// The waitFor waits until the condition is true.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestWatchConfig tests the hot reload of the configuration.
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &syncBuffer{}
+	logger := New("APP")
+	logger.SetOutputs(
+		Output{Name: "console", Writer: buf, Levels: level.AtLeast(level.Info)},
+		Output{Name: "file", Writer: &bytes.Buffer{}, Levels: level.Error},
+	)
+
+	write(`{"outputs": [{"name": "console", "levels": "all"}]}`)
+	w, err := logger.WatchConfig(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if logger.Outputs("console")[0].Levels != level.Default {
+		t.Fatalf("the initial configuration was not applied")
+	}
+
+	if !strings.Contains(buf.String(),
+		"console.levels: panic,fatal,error,warn,info -> "+
+			"panic,fatal,error,warn,info,debug,trace") {
+		t.Errorf("the change was not logged: %s", buf)
+	}
+
+	// Invalid file: the configuration is not changed.
+	write(`{"outputs": [{"name": "console", "levels": "verbose"}]}`)
+	waitFor(t, func() bool { return w.Err() != nil })
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(), "unknown level 'verbose'")
+	})
+
+	// Unknown output: nothing is changed.
+	write(`{"outputs": [
+		{"name": "console", "levels": "error"},
+		{"name": "missing", "levels": "error"}
+	]}`)
+	waitFor(t, func() bool {
+		err := w.Err()
+		return err != nil && strings.Contains(err.Error(), "missing")
+	})
+
+	if logger.Outputs("console")[0].Levels != level.Default {
+		t.Errorf("the configuration was partially applied")
+	}
+
+	// Correct file.
+	write(`{"prefix": "NEW", "outputs": [
+		{"name": "console", "format": "logfmt"},
+		{"name": "file", "levels": ">=warn", "enabled": false}
+	]}`)
+	waitFor(t, func() bool { return logger.Prefix() == "NEW" })
+	if w.Err() != nil {
+		t.Fatal(w.Err())
+	}
+
+	file := logger.Outputs("file")[0]
+	if file.Levels != level.AtLeast(level.Warn) || !file.Enabled.IsFalse() {
+		t.Errorf("incorrect output: %+v", file)
+	}
+
+	out := buf.String()
+	for _, s := range []string{
+		`console.format: text -> logfmt`,
+		`file.levels: error -> panic,fatal,error,warn`,
+		`file.enabled: true -> false`,
+		`prefix: \"APP\" -> \"NEW\"`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("the change %s was not logged: %s", s, out)
+		}
+	}
+}
+
+// TestWatchConfigRevert tests that the parameters removed from the
+// file are restored and the environment variables override the file.
+func TestWatchConfigRevert(t *testing.T) {
+	t.Setenv("LOG_OUTPUTS_FILE_LAYOUTS", "func")
+
+	path := filepath.Join(t.TempDir(), "log.json")
+	logger := New("APP")
+	logger.SetOutputs(
+		Output{Name: "console", Writer: &bytes.Buffer{}, Levels: level.Info},
+		Output{Name: "file", Writer: &bytes.Buffer{}, Levels: level.Error},
+	)
+
+	var w *Watcher
+	apply := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		// The files have different sizes, the change is always seen.
+		var err error
+		if w == nil {
+			w, err = logger.WatchConfig(path, time.Hour)
+		} else {
+			err = w.Reload()
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apply(`{"prefix": "NEW", "outputs": [
+		{"name": "console", "format": "json"},
+		{"name": "file", "levels": "all"}
+	]}`)
+	defer w.Close()
+
+	console, file := logger.Outputs("console")[0], logger.Outputs("file")[0]
+	if outputFormat(&console) != "json" || logger.Prefix() != "NEW" ||
+		file.Levels != level.Default || file.Layouts != layout.FuncName {
+		t.Fatalf("incorrect outputs: %+v %+v", console, file)
+	}
+
+	// The format of the file doesn't prevent the changes of the TextStyle.
+	logger.EditOutputs(Output{Name: "console", TextStyle: trit.True})
+	if o := logger.Outputs("console")[0]; outputFormat(&o) != "text" {
+		t.Errorf("expected text format, got %s", outputFormat(&o))
+	}
+
+	apply(`{"outputs": [
+		{"name": "console", "format": "logfmt"},
+		{"name": "file"}
+	]}`)
+	console, file = logger.Outputs("console")[0], logger.Outputs("file")[0]
+	if outputFormat(&console) != "logfmt" || logger.Prefix() != "APP" ||
+		file.Levels != level.Error || file.Layouts != layout.FuncName {
+		t.Fatalf("incorrect outputs: %+v %+v", console, file)
+	}
+
+	apply(`{"outputs": [{"name": "console", "format": "text", "space": "|"}]}`)
+	console = logger.Outputs("console")[0]
+	if outputFormat(&console) != "text" || console.Space != "|" {
+		t.Errorf("incorrect output: %+v", console)
+	}
+
+	apply(`{}`)
+	console = logger.Outputs("console")[0]
+	if outputFormat(&console) != "text" || console.Space != outSpace ||
+		console.Levels != level.Info {
+		t.Errorf("incorrect output: %+v", console)
+	}
+}
+
+// TestWatchConfigError tests the error at the start of watching.
+func TestWatchConfigError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	if _, err := New().WatchConfig(path, 0); err == nil {
+		t.Error("expected an error for the missing file")
+	}
+
+	data := `{"outputs": [{"name": "stdout", "format": "xml"}]}`
+	os.WriteFile(path, []byte(data), 0o644)
+	if _, err := New().WatchConfig(path, 0); err == nil {
+		t.Error("expected an error for the unknown format")
+	}
+}